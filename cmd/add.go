@@ -2,18 +2,51 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 
+	"github.com/codoworks/sshto/internal/config"
 	"github.com/codoworks/sshto/internal/ui"
 )
 
+// serverFlagFields are the server fields with a flag of their own on add and edit
+var serverFlagFields = []string{"name", "host", "user", "port", "key", "group"}
+
+var serverSets []string
+
 var addCmd = &cobra.Command{
 	Use:   "add",
 	Short: "Add a new server",
-	Long:  `Open an interactive form to add a new server to the configuration.`,
+	Long: `Open an interactive form to add a new server to the configuration.
+
+Given any of the server flags, the server is added without the form, which
+needs at least --name and --host:
+
+  sshto add --name web-1 --host 10.0.0.1 -u deploy -g production --set tags=web`,
+	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		var flagged config.Server
+		given, err := applyServerFlags(cmd, &flagged)
+		if err != nil {
+			return err
+		}
+		if given {
+			if err := checkServer(&flagged); err != nil {
+				return err
+			}
+			if err := App.Config.AddServer(flagged); err != nil {
+				return err
+			}
+			if err := App.Save(); err != nil {
+				return err
+			}
+			fmt.Printf("Server %q added successfully.\n", flagged.Name)
+			return nil
+		}
+
 		model := ui.NewFormModel(nil, App.Config.Groups)
 		p := tea.NewProgram(model)
 
@@ -45,3 +78,78 @@ var addCmd = &cobra.Command{
 		return nil
 	},
 }
+
+// addServerFlags registers the flags that set server fields without the form
+func addServerFlags(cmd *cobra.Command) {
+	cmd.Flags().String("name", "", "server name")
+	cmd.Flags().String("host", "", "hostname or IP address")
+	cmd.Flags().StringP("user", "u", "", "ssh user")
+	cmd.Flags().IntP("port", "p", 0, "ssh port")
+	cmd.Flags().StringP("key", "k", "", "identity file")
+	cmd.Flags().StringP("group", "g", "", "group")
+	cmd.Flags().StringArrayVar(&serverSets, "set", nil, "set a field as field=value, e.g. jump=bastion, tags=web,db or options.ForwardAgent=yes (repeatable)")
+	_ = cmd.RegisterFlagCompletionFunc("key", completeKey)
+	_ = cmd.RegisterFlagCompletionFunc("group", completeGroupFlag)
+	_ = cmd.RegisterFlagCompletionFunc("set", completeSetField)
+}
+
+// applyServerFlags sets the fields given as flags on s, the field flags
+// first and then each --set in order. It reports whether any field was
+// given; if not, the form should be used.
+func applyServerFlags(cmd *cobra.Command, s *config.Server) (bool, error) {
+	if !serverFlagsGiven(cmd) {
+		return false, nil
+	}
+	cmd.SilenceUsage = true
+
+	for _, field := range serverFlagFields {
+		if flag := cmd.Flags().Lookup(field); flag.Changed {
+			if err := s.Set(field, flag.Value.String()); err != nil {
+				return true, err
+			}
+		}
+	}
+	for _, set := range serverSets {
+		field, value, found := strings.Cut(set, "=")
+		if !found {
+			return true, fmt.Errorf("invalid --set %q: expected field=value", set)
+		}
+		if err := s.Set(strings.TrimSpace(field), value); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+// serverFlagsGiven reports whether any server field was given as a flag
+func serverFlagsGiven(cmd *cobra.Command) bool {
+	given := len(serverSets) > 0
+	for _, field := range serverFlagFields {
+		given = given || cmd.Flags().Changed(field)
+	}
+	return given
+}
+
+// checkServer runs the form's checks on a server given by flags. A missing
+// key file is only a warning, as in the form.
+func checkServer(s *config.Server) error {
+	if err := config.ValidateServer(s); err != nil {
+		return err
+	}
+	if err := App.Config.CheckJump(s.Jump); err != nil {
+		return err
+	}
+	warning, err := config.ValidateKeyFile(s.Key)
+	if err != nil {
+		return err
+	}
+	if warning != "" {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+	return nil
+}
+
+func init() {
+	addServerFlags(addCmd)
+	addServerFlags(editCmd)
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/codoworks/sshto/internal/config"
+	"github.com/codoworks/sshto/internal/tunnel"
+)
+
+// completionConfig returns the config to complete names from. Completion
+// requests are parsed after the app is loaded, so a --config given with them
+// is read here.
+func completionConfig() *config.Config {
+	if App != nil && (cfgFile == "" || cfgFile == App.Config.Path()) {
+		return App.Config
+	}
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return nil
+	}
+	return cfg
+}
+
+// completeServer completes the server name of commands taking one server
+func completeServer(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return serverCompletions(nil, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeServers completes server names for commands taking several,
+// leaving out those already given, until the -- before a command
+func completeServers(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if afterDash() {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return serverCompletions(args, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// serverCompletions returns the servers starting with prefix, described by
+// their [user@]host[:port]
+func serverCompletions(skip []string, prefix string) []string {
+	cfg := completionConfig()
+	if cfg == nil {
+		return nil
+	}
+
+	var names []string
+	for _, s := range cfg.Servers {
+		if !strings.HasPrefix(s.Name, prefix) || slices.Contains(skip, s.Name) {
+			continue
+		}
+		names = append(names, s.Name+"\t"+s.Description())
+	}
+	return names
+}
+
+// afterDash reports whether the word being completed comes after --. Cobra
+// strips the -- from the arguments and ArgsLenAtDash is unreliable while
+// completing, so the request itself is checked: the word being completed is
+// last and may be a "--" typed so far.
+func afterDash() bool {
+	return len(os.Args) > 1 && slices.Contains(os.Args[:len(os.Args)-1], "--")
+}
+
+// completeTunnels completes the servers with a running tunnel, leaving out
+// those already given
+func completeTunnels(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cfg := completionConfig()
+	if cfg == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	tunnels, err := tunnel.NewStore(tunnel.Dir(cfg.Path())).List()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var names []string
+	for _, t := range tunnels {
+		if strings.HasPrefix(t.Server, toComplete) && !slices.Contains(args, t.Server) {
+			names = append(names, fmt.Sprintf("%s\tpid %d", t.Server, t.PID))
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeGroup completes the group name of commands taking one group
+func completeGroup(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeGroupFlag(cmd, args, toComplete)
+}
+
+// completeGroupFlag completes a group name as a flag value, described by
+// its path and server count
+func completeGroupFlag(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cfg := completionConfig()
+	if cfg == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var names []string
+	for _, g := range cfg.Groups {
+		if strings.HasPrefix(g.Name, toComplete) {
+			names = append(names, fmt.Sprintf("%s\t%s (%d servers)", g.Name, cfg.GroupPath(g.Name), len(cfg.ServersInGroupTree(g.Name))))
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeSetField completes the field names accepted by --set
+func completeSetField(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var fields []string
+	for _, field := range config.SetFields {
+		field = strings.TrimSuffix(field, "<Key>") + "="
+		if strings.HasSuffix(field, ".=") {
+			field = strings.TrimSuffix(field, "=")
+		}
+		if strings.HasPrefix(field, toComplete) {
+			fields = append(fields, field)
+		}
+	}
+	return fields, cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeKey completes a key file, offering the private keys in ~/.ssh
+// (those with a .pub beside them) before falling back to any file
+func completeKey(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	dir := filepath.Join(home, ".ssh")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+
+	var keys []string
+	for _, e := range entries {
+		path := filepath.Join(dir, e.Name())
+		if e.IsDir() || strings.HasSuffix(path, ".pub") || !strings.HasPrefix(path, toComplete) {
+			continue
+		}
+		if _, err := os.Stat(path + ".pub"); err == nil {
+			keys = append(keys, path)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	return keys, cobra.ShellCompDirectiveNoFileComp
+}
@@ -1,31 +1,131 @@
 package cmd
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/spf13/cobra"
 
+	"github.com/codoworks/sshto/internal/config"
 	"github.com/codoworks/sshto/internal/ssh"
 )
 
-var connectOpts ssh.ConnectOptions
+var (
+	connectOpts       ssh.ConnectOptions
+	connectForwards   []string
+	connectSSHOptions []string
+)
 
 var connectCmd = &cobra.Command{
-	Use:     "connect <server>",
+	Use:     "connect <server> [-- ssh args] [command]",
 	Aliases: []string{"c"},
 	Short:   "Connect to a server",
-	Long:    `Connect to a server by name. Use flags to override config values.`,
-	Args:    cobra.ExactArgs(1),
+	Long: `Connect to a server by name. Use flags to override config values.
+
+Flags for sshto come before the server name. Words after it are run on the
+server instead of a login shell, options included. Arguments right after --
+go to ssh until the first one that is not an option, and the rest is the
+command:
+
+  sshto connect -u root web-1 ls -la
+  sshto connect web-1 -- -A -t 'sudo -i'
+
+A terminal is allocated for the command when sshto runs in one, unless the
+ssh arguments include -t or -T.`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeServer,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return App.Connect(args[0], connectOpts)
+		opts, err := connectOptions()
+		if err != nil {
+			return err
+		}
+		opts.ExtraArgs, opts.Command, err = sessionArgs(cmd, args)
+		if err != nil {
+			return err
+		}
+		cmd.SilenceUsage = true
+
+		name, err := lookupServer(args[0])
+		if err != nil || name == "" {
+			return err
+		}
+
+		if dryRun {
+			return printCommand(App.ConnectCommand(name, opts))
+		}
+		return sessionError(cmd, App.Connect(name, opts))
 	},
 }
 
+// sessionArgs splits the arguments after the server name into ssh arguments
+// and a remote command. Flag parsing stops at the server name (see
+// stopFlagsAtServer), so a -- after it arrives as an argument and marks the
+// ssh arguments.
+func sessionArgs(cmd *cobra.Command, args []string) ([]string, string, error) {
+	if cmd.ArgsLenAtDash() == 0 {
+		return nil, "", fmt.Errorf("a server name must come before --")
+	}
+	rest := args[1:]
+	if len(rest) > 0 && rest[0] == "--" {
+		return ssh.SplitArgs(rest[1:])
+	}
+	return nil, strings.Join(rest, " "), nil
+}
+
+// stopFlagsAtServer makes everything after the server name an argument, so
+// that the options of a remote command (sshto web-1 ls -la) go to the server
+// rather than to sshto
+func stopFlagsAtServer(cmd *cobra.Command) {
+	cmd.Flags().SetInterspersed(false)
+}
+
+// connectOptions returns the connection overrides given on the command line
+func connectOptions() (ssh.ConnectOptions, error) {
+	opts := connectOpts
+	opts.Forwards = nil
+	for _, spec := range connectForwards {
+		f, err := config.ParseForward(spec)
+		if err != nil {
+			return opts, err
+		}
+		opts.Forwards = append(opts.Forwards, f)
+	}
+
+	opts.Options = nil
+	for _, option := range connectSSHOptions {
+		key, value, err := config.ParseOption(option)
+		if err != nil {
+			return opts, err
+		}
+		opts.Options = config.MergeOptions(opts.Options, map[string]string{key: value})
+	}
+	return opts, nil
+}
+
+// addConnectFlags registers the connection override flags on cmd
+func addConnectFlags(cmd *cobra.Command) {
+	addOverrideFlags(cmd)
+	cmd.Flags().StringArrayVar(&connectForwards, "forward", nil, "add a port forward, e.g. L:5432:localhost:5432, R:8080:localhost:80 or D:1080 (repeatable)")
+	cmd.Flags().BoolVar(&connectOpts.NoForwards, "no-forwards", false, "don't open the server's configured port forwards")
+}
+
+// addOverrideFlags registers the overrides that apply to any ssh session,
+// leaving out the forward flags that only make sense for interactive ones
+func addOverrideFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&connectOpts.User, "user", "u", "", "override user")
+	cmd.Flags().IntVarP(&connectOpts.Port, "port", "p", 0, "override port")
+	cmd.Flags().StringVarP(&connectOpts.Key, "key", "k", "", "override key file")
+	_ = cmd.RegisterFlagCompletionFunc("key", completeKey)
+	cmd.Flags().StringVarP(&connectOpts.Jump, "jump", "J", "", "override jump host(s): server name or [user@]host[:port], comma separated, or 'none'")
+	cmd.Flags().StringArrayVarP(&connectSSHOptions, "option", "o", nil, "add an ssh option as Key=Value (repeatable)")
+}
+
 func init() {
-	connectCmd.Flags().StringVarP(&connectOpts.User, "user", "u", "", "override user")
-	connectCmd.Flags().IntVarP(&connectOpts.Port, "port", "p", 0, "override port")
-	connectCmd.Flags().StringVarP(&connectOpts.Key, "key", "k", "", "override key file")
-
-	// Also add these flags to root command for `sshto server --user root` usage
-	rootCmd.Flags().StringVarP(&connectOpts.User, "user", "u", "", "override user")
-	rootCmd.Flags().IntVarP(&connectOpts.Port, "port", "p", 0, "override port")
-	rootCmd.Flags().StringVarP(&connectOpts.Key, "key", "k", "", "override key file")
+	addConnectFlags(connectCmd)
+	addDryRunFlag(connectCmd)
+	stopFlagsAtServer(connectCmd)
+
+	// Also add these flags to root command for `sshto --user root server` usage
+	addConnectFlags(rootCmd)
+	stopFlagsAtServer(rootCmd)
 }
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runDryRun runs sshto with --dry-run against a one-server config and returns
+// what it printed
+func runDryRun(t *testing.T, args ...string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("USERPROFILE", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	cfg := filepath.Join(dir, "config.yaml")
+	data := "servers:\n  - name: web-1\n    host: 10.0.0.1\n    user: deploy\n"
+	if err := os.WriteFile(cfg, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	t.Cleanup(func() {
+		os.Stdout = stdout
+		dryRun = false
+		connectOpts.User = ""
+		rootCmd.SetArgs(nil)
+	})
+
+	rootCmd.SetArgs(append([]string{"--config", cfg, "--dry-run"}, args...))
+	runErr := rootCmd.Execute()
+	w.Close()
+	os.Stdout = stdout
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runErr != nil {
+		t.Fatalf("sshto %s: %v", strings.Join(args, " "), runErr)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestRemoteCommandWithOptions(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"web-1", "ls", "-la"}, "ssh -- deploy@10.0.0.1 'ls -la'"},
+		{[]string{"web-1", "df", "-h"}, "ssh -- deploy@10.0.0.1 'df -h'"},
+		{[]string{"connect", "web-1", "tail", "-n", "5", "/var/log/syslog"}, "ssh -- deploy@10.0.0.1 'tail -n 5 /var/log/syslog'"},
+		{[]string{"-u", "root", "web-1", "--", "-A", "uptime"}, "ssh -A -- root@10.0.0.1 uptime"},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			if got := runDryRun(t, tt.args...); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
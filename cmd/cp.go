@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/codoworks/sshto/internal/ssh"
+)
+
+var copyOpts ssh.CopyOptions
+
+var cpCmd = &cobra.Command{
+	Use:   "cp <source>... <destination> [-- scp/rsync args]",
+	Short: "Copy files to or from a server",
+	Long: `Copy files between this machine and a server with scp, or rsync with --rsync,
+using the server's user, port, key, jump hosts and options from the config.
+
+Write remote paths as server:path. One side must be on a server and the other
+local. Arguments after -- are passed to scp or rsync unchanged.
+
+Example:
+  sshto cp app.tar.gz web:/tmp/
+  sshto cp -r web:/var/log/nginx ./logs
+  sshto cp --rsync ./site/ web:/srv/www/ -- --delete`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		paths := args
+		if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+			paths = args[:dash]
+		}
+		return cobra.MinimumNArgs(2)(cmd, paths)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts, err := connectOptions()
+		if err != nil {
+			return err
+		}
+
+		paths := args
+		copyOpts.Args = nil
+		if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+			paths, copyOpts.Args = args[:dash], args[dash:]
+		}
+
+		if dryRun {
+			return printCommand(App.CopyCommand(paths[:len(paths)-1], paths[len(paths)-1], opts, copyOpts))
+		}
+		return sessionError(cmd, App.Copy(paths[:len(paths)-1], paths[len(paths)-1], opts, copyOpts))
+	},
+}
+
+func init() {
+	cpCmd.Flags().BoolVar(&copyOpts.Rsync, "rsync", false, "copy with rsync -az instead of scp")
+	cpCmd.Flags().BoolVarP(&copyOpts.Recursive, "recursive", "r", false, "copy directories (rsync always does)")
+	addDryRunFlag(cpCmd)
+	addOverrideFlags(cpCmd)
+}
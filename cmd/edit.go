@@ -6,25 +6,57 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 
+	"github.com/codoworks/sshto/internal/config"
 	"github.com/codoworks/sshto/internal/ui"
 )
 
 var editCmd = &cobra.Command{
 	Use:   "edit <server>",
 	Short: "Edit an existing server",
-	Long:  `Open an interactive form to edit an existing server configuration.`,
-	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		serverName := args[0]
+	Long: `Open an interactive form to edit an existing server configuration.
 
-		server, err := App.Config.FindServer(serverName)
-		if err != nil {
+Given any of the server flags, they are applied without the form. --set
+changes any field by its config name, and an empty value clears it:
+
+  sshto edit web-1 --host 10.0.0.2 --set jump= --set options.ForwardAgent=yes
+
+The form finds the server as connect does. Flags change it unseen, so they
+need its exact name, or one that only it matches ignoring case.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeServer,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		server, err := editTarget(cmd, args[0])
+		if err != nil || server == nil {
 			return err
 		}
+		serverName := server.Name
 
 		// Make a copy for editing
 		serverCopy := *server
 
+		given, err := applyServerFlags(cmd, &serverCopy)
+		if err != nil {
+			return err
+		}
+		if given {
+			if err := checkServer(&serverCopy); err != nil {
+				return err
+			}
+			if serverCopy.Name != serverName {
+				if _, err := App.Config.FindServer(serverCopy.Name); err == nil {
+					return fmt.Errorf("server %q already exists", serverCopy.Name)
+				}
+			}
+			if err := App.Config.UpdateServer(serverName, serverCopy); err != nil {
+				return err
+			}
+			if err := App.Save(); err != nil {
+				return err
+			}
+			fmt.Printf("Server %q updated successfully.\n", serverCopy.Name)
+			return nil
+		}
+
 		model := ui.NewFormModel(&serverCopy, App.Config.Groups)
 		p := tea.NewProgram(model)
 
@@ -56,3 +88,16 @@ var editCmd = &cobra.Command{
 		return nil
 	},
 }
+
+// editTarget returns the server to edit, or nil if the list of matches was
+// closed without choosing one
+func editTarget(cmd *cobra.Command, name string) (*config.Server, error) {
+	if serverFlagsGiven(cmd) {
+		return App.Config.FindServerFold(name)
+	}
+	serverName, err := lookupServer(name)
+	if err != nil || serverName == "" {
+		return nil, err
+	}
+	return App.Config.FindServer(serverName)
+}
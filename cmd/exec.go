@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/codoworks/sshto/internal/app"
+	"github.com/codoworks/sshto/internal/ui"
+)
+
+var (
+	execGroup    string
+	execAll      bool
+	execParallel int
+	execBuffer   bool
+)
+
+var execCmd = &cobra.Command{
+	Use:   "exec [servers...] -- <command>",
+	Short: "Run a command on several servers in parallel",
+	Long: `Run a command on several servers at once and report how each one went.
+
+Targets are the named servers, or every server when none are named, narrowed
+down by --group and --tag. Use --all to run on every server without a filter.
+Output lines are prefixed with the server name; use --buffer to print each
+server's output as one block when it finishes instead.
+
+Sessions never prompt for passwords or host keys, so keys or an agent must
+be set up. The exit status is non-zero when any server failed.
+
+Example:
+  sshto exec -g production -- systemctl status nginx
+  sshto exec web1 web2 --parallel 1 -- uptime`,
+	ValidArgsFunction: completeServers,
+	Args: func(cmd *cobra.Command, args []string) error {
+		dash := cmd.ArgsLenAtDash()
+		if dash < 0 || dash == len(args) {
+			return fmt.Errorf("missing command: use sshto exec [servers...] -- <command>")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dash := cmd.ArgsLenAtDash()
+		command := strings.Join(args[dash:], " ")
+
+		opts, err := connectOptions()
+		if err != nil {
+			return err
+		}
+
+		if dash == 0 && execGroup == "" && len(filterTags) == 0 && !execAll {
+			return fmt.Errorf("no servers selected: name them or use --group, --tag or --all")
+		}
+		names, err := selectServers(args[:dash], execGroup)
+		if err != nil {
+			return err
+		}
+		cmd.SilenceUsage = true
+
+		if dryRun {
+			commands, err := App.ExecCommands(names, command, opts)
+			if err != nil {
+				return err
+			}
+			for _, c := range commands {
+				fmt.Println(c)
+			}
+			return nil
+		}
+
+		out := newExecOutput(names, execBuffer)
+		results, err := App.Exec(names, command, app.ExecOptions{
+			Connect:  opts,
+			Parallel: execParallel,
+			Output:   out.output,
+			Done:     out.done,
+		})
+		if err != nil {
+			return err
+		}
+
+		failed := printExecSummary(results)
+		if failed > 0 {
+			return resultError{fmt.Errorf("%d of %d servers failed", failed, len(results))}
+		}
+		return nil
+	},
+}
+
+// execOutput routes each server's output to the terminal, either line by
+// line with a name prefix or as one block per server
+type execOutput struct {
+	width  int
+	buffer bool
+
+	mu       sync.Mutex // guards the terminal and the maps below
+	prefixed map[string][2]*ui.PrefixWriter
+	buffers  map[string]*lockedBuffer
+}
+
+func newExecOutput(names []string, buffer bool) *execOutput {
+	width := 0
+	for _, name := range names {
+		width = max(width, len(name))
+	}
+	return &execOutput{
+		width:    width,
+		buffer:   buffer,
+		prefixed: make(map[string][2]*ui.PrefixWriter),
+		buffers:  make(map[string]*lockedBuffer),
+	}
+}
+
+// output implements app.ExecOptions.Output
+func (o *execOutput) output(server string) (io.Writer, io.Writer) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.buffer {
+		// Both streams share the buffer to keep their order, and the native
+		// transport writes them from separate goroutines
+		buf := &lockedBuffer{}
+		o.buffers[server] = buf
+		return buf, buf
+	}
+
+	prefix := fmt.Sprintf("%-*s | ", o.width, server)
+	stdout := ui.NewPrefixWriter(os.Stdout, &o.mu, prefix)
+	stderr := ui.NewPrefixWriter(os.Stderr, &o.mu, prefix)
+	o.prefixed[server] = [2]*ui.PrefixWriter{stdout, stderr}
+	return stdout, stderr
+}
+
+// done implements app.ExecOptions.Done
+func (o *execOutput) done(r app.ExecResult) {
+	if !o.buffer {
+		o.mu.Lock()
+		w := o.prefixed[r.Server]
+		o.mu.Unlock()
+		for _, pw := range w {
+			pw.Flush()
+		}
+		return
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	fmt.Printf("==> %s (%s) <==\n", r.Server, execStatus(r))
+	out := o.buffers[r.Server].Bytes()
+	os.Stdout.Write(out)
+	if len(out) > 0 && out[len(out)-1] != '\n' {
+		fmt.Println()
+	}
+}
+
+// lockedBuffer is a buffer that can be written from several goroutines
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// Bytes returns the buffered output
+func (b *lockedBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Bytes()
+}
+
+// execStatus describes how a run ended
+func execStatus(r app.ExecResult) string {
+	switch {
+	case r.Err == nil:
+		return "ok"
+	case r.ExitCode < 0:
+		return "error: " + r.Err.Error()
+	case r.ExitCode == 255:
+		return "ssh error"
+	default:
+		return "failed"
+	}
+}
+
+// printExecSummary prints one line per server and returns the number that failed
+func printExecSummary(results []app.ExecResult) int {
+	failed := 0
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SERVER\tSTATUS\tEXIT\tDURATION")
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+		}
+		code := "-"
+		if r.ExitCode >= 0 {
+			code = fmt.Sprint(r.ExitCode)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Server, execStatus(r), code, r.Duration.Round(10*time.Millisecond))
+	}
+	w.Flush()
+	return failed
+}
+
+func init() {
+	execCmd.Flags().StringVarP(&execGroup, "group", "g", "", "run on servers in this group and its nested groups")
+	_ = execCmd.RegisterFlagCompletionFunc("group", completeGroupFlag)
+	execCmd.Flags().BoolVar(&execAll, "all", false, "run on every server")
+	execCmd.Flags().IntVarP(&execParallel, "parallel", "P", 10, "maximum number of servers to run on at once")
+	execCmd.Flags().BoolVar(&execBuffer, "buffer", false, "print each server's output as one block when it finishes")
+	addDryRunFlag(execCmd)
+	addTagFlags(execCmd)
+	addOverrideFlags(execCmd)
+}
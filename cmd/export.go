@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/codoworks/sshto/internal/config"
+	"github.com/codoworks/sshto/internal/ssh"
+	"github.com/codoworks/sshto/internal/sshconfig"
+)
+
+var (
+	exportWrite  bool
+	exportOutput string
+	exportForce  bool
+
+	exportForwards bool
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export servers to other formats",
+}
+
+var exportSSHConfigCmd = &cobra.Command{
+	Use:   "ssh-config",
+	Short: "Export servers as an ssh_config file",
+	Long: `Render every server as an OpenSSH Host block, with defaults applied.
+
+By default the result is printed to stdout. With --write it is saved to a
+managed file (default ssh_config next to the sshto config) that can be
+referenced from the top of ~/.ssh/config:
+
+  Include ~/.config/sshto/ssh_config
+
+Forwards are left out unless --forwards is given: every ssh, git or editor
+connection through the Host block would try to bind their ports, clashing
+with each other and with sshto tunnels.
+
+With --tag, the servers that the selected ones jump through are exported
+too, since their ProxyJump lines name them.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		selected := filterByTags(App.Config.Servers)
+		servers := make([]config.Server, 0, len(selected))
+		exported := make(map[string]bool)
+		for _, s := range selected {
+			resolved, err := exportServer(s.Name)
+			if err != nil {
+				// One broken server must not keep the others out of ssh's reach
+				fmt.Fprintf(os.Stderr, "Warning: skipped %q: %v\n", s.Name, err)
+				continue
+			}
+			servers = append(servers, *resolved)
+			exported[s.Name] = true
+		}
+
+		// ProxyJump names the servers a chain goes through, so each needs its
+		// Host block even when --tag left it out. The chains are flattened:
+		// every hop is already listed.
+		for i := range len(servers) {
+			for _, hop := range servers[i].JumpChain {
+				if exported[hop.Name] {
+					continue
+				}
+				if _, err := App.Config.FindServer(hop.Name); err != nil {
+					continue // a raw [user@]host[:port], written as is
+				}
+				resolved, err := exportServer(hop.Name)
+				if err != nil {
+					continue
+				}
+				servers = append(servers, *resolved)
+				exported[hop.Name] = true
+			}
+		}
+
+		var skipped []string
+		var err error
+		if exportWrite {
+			path := exportOutput
+			if path == "" {
+				path = sshconfig.ManagedPath(App.Config.Path())
+			}
+			skipped, err = sshconfig.WriteManaged(config.ExpandPath(path), servers, exportForce)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Wrote %d server(s) to %s.\n", len(servers)-len(skipped), path)
+		} else {
+			skipped, err = sshconfig.Render(os.Stdout, servers)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, name := range skipped {
+			fmt.Fprintf(os.Stderr, "Warning: skipped %q (name is not a valid Host pattern)\n", name)
+		}
+		return nil
+	},
+}
+
+// exportServer resolves a server for export
+func exportServer(name string) (*config.Server, error) {
+	resolved, err := App.Resolve(name, ssh.ConnectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if !exportForwards {
+		resolved.Forwards = nil
+	}
+	return resolved, nil
+}
+
+func init() {
+	exportSSHConfigCmd.Flags().BoolVarP(&exportWrite, "write", "w", false, "write the managed ssh_config file instead of printing")
+	exportSSHConfigCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "path of the managed file (default next to the config file)")
+	exportSSHConfigCmd.Flags().BoolVarP(&exportForce, "force", "f", false, "overwrite the output file even if sshto did not create it")
+	exportSSHConfigCmd.Flags().BoolVar(&exportForwards, "forwards", false, "include servers' forwards as LocalForward, RemoteForward and DynamicForward")
+	addTagFlags(exportSSHConfigCmd)
+
+	exportCmd.AddCommand(exportSSHConfigCmd)
+}
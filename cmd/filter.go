@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/codoworks/sshto/internal/config"
+	"github.com/codoworks/sshto/internal/ui"
+)
+
+var (
+	filterTags    []string
+	filterAllTags bool
+)
+
+// addTagFlags registers the tag filter flags on cmd
+func addTagFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&filterTags, "tag", nil, "only include servers with this tag (repeatable or comma separated)")
+	cmd.Flags().BoolVar(&filterAllTags, "all-tags", false, "require every --tag instead of any of them")
+}
+
+// filterByTags applies the --tag and --all-tags flags to servers
+func filterByTags(servers []config.Server) []config.Server {
+	return ui.FilterByTags(servers, filterTags, filterAllTags)
+}
+
+// selectServers returns the names of the named servers, or of every server
+// when none are named, narrowed down by group and the tag flags
+func selectServers(names []string, group string) ([]string, error) {
+	servers := App.Config.Servers
+	if len(names) > 0 {
+		servers = nil
+		seen := make(map[string]bool)
+		for _, name := range names {
+			s, err := App.Config.FindServer(name)
+			if err != nil {
+				return nil, err
+			}
+			if !seen[s.Name] {
+				seen[s.Name] = true
+				servers = append(servers, *s)
+			}
+		}
+	}
+	if group != "" {
+		servers = ui.FilterByGroupTree(servers, App.Config.Groups, group)
+	}
+	servers = filterByTags(servers)
+
+	if len(servers) == 0 {
+		return nil, fmt.Errorf("no servers match the given filters")
+	}
+
+	selected := make([]string, len(servers))
+	for i, s := range servers {
+		selected[i] = s.Name
+	}
+	return selected, nil
+}
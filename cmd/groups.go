@@ -9,32 +9,92 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/codoworks/sshto/internal/config"
+	"github.com/codoworks/sshto/internal/output"
 	"github.com/codoworks/sshto/internal/ui"
 )
 
+var groupsParent string
+
 var groupsCmd = &cobra.Command{
 	Use:   "groups",
 	Short: "List all groups",
-	Long:  `List all configured server groups.`,
-	Run: func(cmd *cobra.Command, args []string) {
+	Long: `List all configured server groups as a tree, with nested groups below their parent.
+
+With --output, or when stdout is not a terminal, they are printed as a table,
+json, yaml, names (one per line) or a Go template given with --template instead.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, given, err := outputFormat()
+		if err != nil {
+			return err
+		}
+		if given || !isTerminal(os.Stdout) {
+			if !given {
+				format = output.Table
+			}
+			cmd.SilenceUsage = true
+			records := make([]groupRecord, len(App.Config.Groups))
+			for i := range App.Config.Groups {
+				records[i] = newGroupRecord(App.Config, &App.Config.Groups[i])
+			}
+			return groupView.Print(os.Stdout, format, outputTemplate, records)
+		}
+
 		if len(App.Config.Groups) == 0 {
 			fmt.Println("No groups configured. Use 'sshto groups add' to create one.")
-			return
+			return nil
 		}
 
+		seen := make(map[string]bool)
+		printGroupTree("", "", seen)
+
+		// Groups caught in a parent cycle never hang off the top level
 		for _, g := range App.Config.Groups {
-			tag := ui.GroupTag(g.Name, g.Color)
-			serverCount := len(App.Config.ServersByGroup(g.Name))
-			fmt.Printf("%s (%d servers)\n", tag, serverCount)
+			if !seen[g.Name] {
+				fmt.Printf("%s (parent cycle via %q)\n", ui.GroupTag(g.Name, g.Color), g.Parent)
+			}
 		}
+		return nil
 	},
 }
 
+// printGroupTree prints the children of parent, each prefixed with tree branches
+func printGroupTree(parent, indent string, seen map[string]bool) {
+	children := App.Config.GroupChildren(parent)
+	for i, g := range children {
+		if seen[g.Name] {
+			continue
+		}
+		seen[g.Name] = true
+
+		branch, next := "", ""
+		if parent != "" {
+			branch, next = "├── ", "│   "
+			if i == len(children)-1 {
+				branch, next = "└── ", "    "
+			}
+		}
+
+		tag := ui.GroupTag(g.Name, g.Color)
+		direct := len(App.Config.ServersByGroup(g.Name))
+		total := len(App.Config.ServersInGroupTree(g.Name))
+		if total != direct {
+			fmt.Printf("%s%s%s (%d servers, %d including subgroups)\n", indent, branch, tag, direct, total)
+		} else {
+			fmt.Printf("%s%s%s (%d servers)\n", indent, branch, tag, direct)
+		}
+
+		printGroupTree(g.Name, indent+next, seen)
+	}
+}
+
 var groupsAddCmd = &cobra.Command{
 	Use:   "add <name> [color]",
 	Short: "Add a new group",
 	Long: `Add a new group with an optional color.
-Available colors: red, green, yellow, blue, magenta, cyan, white, gray`,
+Available colors: red, green, yellow, blue, magenta, cyan, white, gray
+
+Use --parent to nest the group below another one. Servers in a nested group
+inherit connection settings from every group above it.`,
 	Args: cobra.RangeArgs(1, 2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		name := args[0]
@@ -44,8 +104,9 @@ Available colors: red, green, yellow, blue, magenta, cyan, white, gray`,
 		}
 
 		group := config.Group{
-			Name:  name,
-			Color: color,
+			Name:   name,
+			Color:  color,
+			Parent: groupsParent,
 		}
 
 		if err := App.Config.AddGroup(group); err != nil {
@@ -65,8 +126,10 @@ var groupsRemoveCmd = &cobra.Command{
 	Use:     "remove <name>",
 	Aliases: []string{"rm"},
 	Short:   "Remove a group",
-	Long:    `Remove a group from the configuration. Servers in this group will not be deleted.`,
-	Args:    cobra.ExactArgs(1),
+	Long: `Remove a group from the configuration. Servers in this group will not be deleted.
+Nested groups move up to the removed group's parent.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeGroup,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		name := args[0]
 
@@ -105,6 +168,11 @@ var groupsRemoveCmd = &cobra.Command{
 }
 
 func init() {
+	groupsAddCmd.Flags().StringVarP(&groupsParent, "parent", "p", "", "nest the group below an existing group")
+	_ = groupsAddCmd.RegisterFlagCompletionFunc("parent", completeGroupFlag)
+
+	addOutputFlags(groupsCmd)
+
 	groupsCmd.AddCommand(groupsAddCmd)
 	groupsCmd.AddCommand(groupsRemoveCmd)
 }
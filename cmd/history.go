@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/codoworks/sshto/internal/history"
+)
+
+var (
+	historyLimit int
+	historyClear bool
+	historyTop   bool
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history [server]",
+	Short: "Show recent connections",
+	Long: `Show recent connections, newest first, optionally for a single server.
+
+Use --top to rank servers by frecency, the order the interactive list uses.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeServer,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		hist := App.History
+		if hist == nil {
+			return fmt.Errorf("connection history is not available")
+		}
+
+		if historyClear {
+			if _, err := history.Update(hist.Path(), (*history.Store).Clear); err != nil {
+				return err
+			}
+			fmt.Println("History cleared.")
+			return nil
+		}
+
+		if historyTop {
+			printTopServers(hist)
+			return nil
+		}
+
+		server := ""
+		if len(args) == 1 {
+			server = args[0]
+		}
+
+		entries := hist.Recent(server, historyLimit)
+		if len(entries) == 0 {
+			fmt.Println("No connections recorded yet.")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TIME\tSERVER\tDURATION\tEXIT")
+		for _, e := range entries {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\n", e.Time.Local().Format("2006-01-02 15:04"), e.Server, e.Duration, e.ExitCode)
+		}
+		return w.Flush()
+	},
+}
+
+// printTopServers prints servers ranked by frecency
+func printTopServers(hist *history.Store) {
+	scores := hist.Scores(time.Now())
+	if len(scores) == 0 {
+		fmt.Println("No connections recorded yet.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SERVER\tSCORE\tCONNECTIONS\tLAST")
+	for i, name := range history.Ranked(scores) {
+		if historyLimit > 0 && i == historyLimit {
+			break
+		}
+		recent := hist.Recent(name, 0)
+		fmt.Fprintf(w, "%s\t%.0f\t%d\t%s\n", name, scores[name], len(recent), recent[0].Time.Local().Format("2006-01-02 15:04"))
+	}
+	w.Flush()
+}
+
+func init() {
+	historyCmd.Flags().IntVarP(&historyLimit, "limit", "n", 20, "number of entries to show (0 for all)")
+	historyCmd.Flags().BoolVar(&historyClear, "clear", false, "delete the connection history")
+	historyCmd.Flags().BoolVar(&historyTop, "top", false, "rank servers by frecency instead of listing connections")
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/codoworks/sshto/internal/sshconfig"
+)
+
+var (
+	importOnConflict string
+	importGroup      string
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import servers from other sources",
+}
+
+var importSSHConfigCmd = &cobra.Command{
+	Use:   "ssh-config [path]",
+	Short: "Import servers from an ssh_config file",
+	Long: `Import Host entries from an OpenSSH client config (default ~/.ssh/config).
+
+Include directives are followed. Wildcard and negated Host patterns are skipped.
+Use --dry-run to preview the changes and --on-conflict to choose what happens
+when a server with the same name already exists (skip, overwrite or rename).`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := sshconfig.DefaultPath()
+		if len(args) == 1 {
+			path = args[0]
+		}
+
+		policy, err := sshconfig.ParseConflictPolicy(importOnConflict)
+		if err != nil {
+			return err
+		}
+
+		if importGroup != "" {
+			if _, err := App.Config.FindGroup(importGroup); err != nil {
+				return err
+			}
+		}
+
+		hosts, err := sshconfig.Parse(path)
+		if err != nil {
+			return err
+		}
+
+		if len(hosts) == 0 {
+			fmt.Printf("No hosts found in %s.\n", path)
+			return nil
+		}
+
+		items := sshconfig.PlanImport(App.Config, hosts, policy, importGroup)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ACTION\tNAME\tDESTINATION\tNOTE")
+		for _, item := range items {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", item.Action, item.Server.Name, item.Server.Description(), item.Reason)
+		}
+		w.Flush()
+
+		if dryRun {
+			fmt.Println("\nDry run: no changes written.")
+			return nil
+		}
+
+		applied, err := sshconfig.ApplyImport(App.Config, items)
+		if err != nil {
+			return err
+		}
+
+		if applied == 0 {
+			fmt.Println("\nNothing to import.")
+			return nil
+		}
+
+		if err := App.Save(); err != nil {
+			return err
+		}
+
+		fmt.Printf("\nImported %d server(s).\n", applied)
+		return nil
+	},
+}
+
+func init() {
+	// Shares the variable of the other commands' --dry-run, adding -n
+	importSSHConfigCmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "preview without writing the config")
+	importSSHConfigCmd.Flags().StringVar(&importOnConflict, "on-conflict", string(sshconfig.ConflictSkip), "what to do with existing names: skip, overwrite or rename")
+	importSSHConfigCmd.Flags().StringVarP(&importGroup, "group", "g", "", "assign imported servers to a group")
+	_ = importSSHConfigCmd.RegisterFlagCompletionFunc("group", completeGroupFlag)
+
+	importCmd.AddCommand(importSSHConfigCmd)
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var lastCmd = &cobra.Command{
+	Use:   "last",
+	Short: "Reconnect to the last server",
+	Long: `Reconnect to the server of the most recent connection, replaying the
+--user, --port, --key, --jump, --forward and --option overrides it used.
+Flags given now are applied on top. 'sshto -' is a shortcut.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts, err := connectOptions()
+		if err != nil {
+			return err
+		}
+		cmd.SilenceUsage = true
+
+		name, opts, err := App.LastConnection(opts)
+		if err != nil {
+			return err
+		}
+
+		if dryRun {
+			return printCommand(App.ConnectCommand(name, opts))
+		}
+		fmt.Printf("Reconnecting to %s...\n", name)
+		return sessionError(cmd, App.Connect(name, opts))
+	},
+}
+
+func init() {
+	addConnectFlags(lastCmd)
+	addDryRunFlag(lastCmd)
+}
@@ -1,56 +1,154 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 
+	"github.com/codoworks/sshto/internal/config"
+	"github.com/codoworks/sshto/internal/output"
+	"github.com/codoworks/sshto/internal/probe"
 	"github.com/codoworks/sshto/internal/ssh"
 	"github.com/codoworks/sshto/internal/ui"
 )
 
-var listGroup string
+var (
+	listGroup    string
+	listDirect   bool
+	listSort     string
+	listNoStatus bool
+)
 
 var listCmd = &cobra.Command{
 	Use:     "list",
 	Aliases: []string{"ls", "l"},
 	Short:   "Interactive server selection",
-	Long:    `Open an interactive fuzzy-filterable list of servers to connect to.`,
+	Long: `Open an interactive fuzzy-filterable list of servers to connect to.
+
+Filtering by group includes servers in its nested groups unless --direct is set.
+Filtering by --tag keeps servers with any of the tags, or all of them with --all-tags.
+Press space to fold or unfold the selected server's group.
+
+Servers are ordered by frecency (how often and how recently you connected),
+so the most likely target is preselected. Use --sort config for file order.
+
+The ssh port of each server on screen is checked in the background and shown
+as a green or red dot with its latency. Use --no-status to skip the checks.
+
+With --output, or when stdout is not a terminal, the servers are printed in
+config order instead, with group settings and defaults applied: as a table,
+json, yaml, names (one per line) or a Go template given with --template:
+
+  sshto list --output json
+  sshto list -g production --template '{{.User}}@{{.Host}}:{{.Port}}'`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		opts, err := connectOptions()
+		if err != nil {
+			return err
+		}
+
 		servers := App.Config.Servers
 		if listGroup != "" {
-			servers = ui.FilterByGroup(servers, listGroup)
+			if listDirect {
+				servers = ui.FilterByGroup(servers, listGroup)
+			} else {
+				servers = ui.FilterByGroupTree(servers, App.Config.Groups, listGroup)
+			}
+		}
+		servers = filterByTags(servers)
+
+		format, given, err := outputFormat()
+		if err != nil {
+			return err
+		}
+		if given || !isTerminal(os.Stdout) {
+			if !given {
+				format = output.Table
+			}
+			cmd.SilenceUsage = true
+			return printServers(format, servers)
 		}
 
 		if len(servers) == 0 {
+			if len(App.Config.Servers) > 0 {
+				fmt.Println("No servers match the given filters.")
+				return nil
+			}
 			fmt.Println("No servers configured. Use 'sshto add' to add a server.")
 			return nil
 		}
 
-		model := ui.NewListModel(servers, App.Config.Groups)
-		p := tea.NewProgram(model, tea.WithAltScreen())
+		var listOpts []ui.ListOption
+		switch listSort {
+		case "frecency":
+			listOpts = append(listOpts, ui.WithScores(App.Scores()))
+		case "config":
+		default:
+			return fmt.Errorf("invalid --sort %q (use frecency or config)", listSort)
+		}
+		cmd.SilenceUsage = true
 
-		finalModel, err := p.Run()
-		if err != nil {
-			return err
+		if !listNoStatus {
+			listOpts = append(listOpts, ui.WithProbe(func(ctx context.Context, s config.Server) probe.Result {
+				return App.Ping(ctx, s.Name, 2*time.Second)
+			}))
 		}
 
-		m := finalModel.(ui.ListModel)
-		selected := m.Selected()
-		if selected == nil {
-			return nil
+		selected, err := pickServer(servers, listOpts...)
+		if err != nil || selected == nil {
+			return err
 		}
 
+		if dryRun {
+			return printCommand(App.ConnectCommand(selected.Name, opts))
+		}
 		fmt.Printf("Connecting to %s...\n", selected.Name)
-		return App.Connect(selected.Name, ssh.ConnectOptions{
-			User: connectOpts.User,
-			Port: connectOpts.Port,
-			Key:  connectOpts.Key,
-		})
+		return sessionError(cmd, App.Connect(selected.Name, opts))
 	},
 }
 
+// pickServer opens the interactive list of servers and returns the one
+// chosen, or nil when the list was closed without choosing
+func pickServer(servers []config.Server, opts ...ui.ListOption) (*config.Server, error) {
+	model := ui.NewListModel(servers, App.Config.Groups, opts...)
+	p := tea.NewProgram(model, tea.WithAltScreen())
+
+	finalModel, err := p.Run()
+	if err != nil {
+		return nil, err
+	}
+	return finalModel.(ui.ListModel).Selected(), nil
+}
+
+// printServers prints the resolved servers in the given format
+func printServers(format output.Format, servers []config.Server) error {
+	records := make([]serverRecord, 0, len(servers))
+	for _, s := range servers {
+		resolved, err := App.Resolve(s.Name, ssh.ConnectOptions{})
+		if err != nil {
+			// Keep listing: the stored settings still identify the server
+			fmt.Fprintf(os.Stderr, "Warning: %s: %v (showing its stored settings)\n", s.Name, err)
+			resolved = &s
+		}
+		records = append(records, newServerRecord(resolved))
+	}
+	return serverView.Print(os.Stdout, format, outputTemplate, records)
+}
+
 func init() {
 	listCmd.Flags().StringVarP(&listGroup, "group", "g", "", "filter by group")
+	_ = listCmd.RegisterFlagCompletionFunc("group", completeGroupFlag)
+	listCmd.Flags().BoolVar(&listDirect, "direct", false, "exclude servers in nested groups when filtering by group")
+	listCmd.Flags().StringVar(&listSort, "sort", "frecency", "server order: frecency or config")
+	listCmd.Flags().BoolVar(&listNoStatus, "no-status", false, "don't check whether servers are reachable")
+	addTagFlags(listCmd)
+	addOutputFlags(listCmd)
+	addDryRunFlag(listCmd)
+
+	// Root runs the list when called without a server
+	addTagFlags(rootCmd)
 }
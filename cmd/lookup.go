@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/codoworks/sshto/internal/ui"
+)
+
+// lookupServer returns the configured name of the server a name given on
+// the command line refers to, matching it as config.LookupServer does. When
+// several servers match, the interactive list opens with just those; an
+// empty name means it was closed without choosing one.
+func lookupServer(name string) (string, error) {
+	matches, err := App.Config.LookupServer(name)
+	if err != nil {
+		return "", err
+	}
+	if len(matches) == 1 {
+		return matches[0].Name, nil
+	}
+
+	if !isTerminal(os.Stdin) || !isTerminal(os.Stdout) {
+		names := make([]string, len(matches))
+		for i, s := range matches {
+			names[i] = s.Name
+		}
+		return "", fmt.Errorf("%q matches several servers: %s", name, strings.Join(names, ", "))
+	}
+
+	selected, err := pickServer(matches, ui.WithScores(App.Scores()))
+	if err != nil || selected == nil {
+		return "", err
+	}
+	return selected.Name, nil
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+
+	"github.com/codoworks/sshto/internal/config"
+	"github.com/codoworks/sshto/internal/output"
+)
+
+var (
+	outputFlag     string
+	outputTemplate string
+)
+
+// addOutputFlags registers --output and --template on cmd
+func addOutputFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&outputFlag, "output", "", "print as table, json, yaml, names or template instead")
+	cmd.Flags().StringVar(&outputTemplate, "template", "", "Go template printed for each item, e.g. '{{.Name}} {{.Host}}' (implies --output template)")
+	_ = cmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		names := make([]string, len(output.Formats))
+		for i, f := range output.Formats {
+			names[i] = string(f)
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp
+	})
+}
+
+// outputFormat returns the format asked for with --output or --template.
+// ok is false when neither was given.
+func outputFormat() (format output.Format, ok bool, err error) {
+	switch {
+	case outputFlag != "":
+		format, err = output.ParseFormat(outputFlag)
+		return format, true, err
+	case outputTemplate != "":
+		return output.Template, true, nil
+	}
+	return "", false, nil
+}
+
+// isTerminal reports whether f is a terminal
+func isTerminal(f *os.File) bool {
+	fd := f.Fd()
+	return isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd)
+}
+
+// serverRecord is the printed form of a resolved server
+type serverRecord struct {
+	Name      string            `json:"name" yaml:"name"`
+	Host      string            `json:"host" yaml:"host"`
+	User      string            `json:"user,omitempty" yaml:"user,omitempty"`
+	Port      int               `json:"port,omitempty" yaml:"port,omitempty"`
+	Key       string            `json:"key,omitempty" yaml:"key,omitempty"`
+	Group     string            `json:"group,omitempty" yaml:"group,omitempty"`
+	Jump      string            `json:"jump,omitempty" yaml:"jump,omitempty"`
+	Transport string            `json:"transport,omitempty" yaml:"transport,omitempty"`
+	Tags      []string          `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Forwards  []string          `json:"forwards,omitempty" yaml:"forwards,omitempty"`
+	Options   map[string]string `json:"options,omitempty" yaml:"options,omitempty"`
+}
+
+func newServerRecord(s *config.Server) serverRecord {
+	r := serverRecord{
+		Name:      s.Name,
+		Host:      s.Host,
+		User:      s.User,
+		Port:      s.Port,
+		Key:       s.Key,
+		Group:     s.Group,
+		Jump:      s.Jump,
+		Transport: s.Transport,
+		Tags:      s.Tags,
+		Options:   s.Options,
+	}
+	for _, f := range s.Forwards {
+		r.Forwards = append(r.Forwards, f.String())
+	}
+	return r
+}
+
+var serverView = output.View[serverRecord]{
+	Header: []string{"NAME", "HOST", "USER", "PORT", "GROUP", "JUMP", "TAGS"},
+	Row: func(r serverRecord) []string {
+		port := ""
+		if r.Port != 0 {
+			port = strconv.Itoa(r.Port)
+		}
+		return []string{r.Name, r.Host, r.User, port, r.Group, r.Jump, strings.Join(r.Tags, ",")}
+	},
+	Name: func(r serverRecord) string { return r.Name },
+}
+
+// groupRecord is the printed form of a group
+type groupRecord struct {
+	Name     string            `json:"name" yaml:"name"`
+	Parent   string            `json:"parent,omitempty" yaml:"parent,omitempty"`
+	Path     string            `json:"path" yaml:"path"`
+	Color    string            `json:"color,omitempty" yaml:"color,omitempty"`
+	User     string            `json:"user,omitempty" yaml:"user,omitempty"`
+	Port     int               `json:"port,omitempty" yaml:"port,omitempty"`
+	Key      string            `json:"key,omitempty" yaml:"key,omitempty"`
+	Jump     string            `json:"jump,omitempty" yaml:"jump,omitempty"`
+	Forwards []string          `json:"forwards,omitempty" yaml:"forwards,omitempty"`
+	Options  map[string]string `json:"options,omitempty" yaml:"options,omitempty"`
+	Servers  int               `json:"servers" yaml:"servers"` // servers directly in the group
+	Total    int               `json:"total" yaml:"total"`     // including nested groups
+}
+
+func newGroupRecord(cfg *config.Config, g *config.Group) groupRecord {
+	r := groupRecord{
+		Name:    g.Name,
+		Parent:  g.Parent,
+		Path:    cfg.GroupPath(g.Name),
+		Color:   g.Color,
+		User:    g.User,
+		Port:    g.Port,
+		Key:     g.Key,
+		Jump:    g.Jump,
+		Options: g.Options,
+		Servers: len(cfg.ServersByGroup(g.Name)),
+		Total:   len(cfg.ServersInGroupTree(g.Name)),
+	}
+	for _, f := range g.Forwards {
+		r.Forwards = append(r.Forwards, f.String())
+	}
+	return r
+}
+
+var groupView = output.View[groupRecord]{
+	Header: []string{"NAME", "PATH", "COLOR", "SERVERS", "TOTAL"},
+	Row: func(r groupRecord) []string {
+		return []string{r.Name, r.Path, r.Color, strconv.Itoa(r.Servers), strconv.Itoa(r.Total)}
+	},
+	Name: func(r groupRecord) string { return r.Name },
+}
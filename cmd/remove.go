@@ -12,11 +12,12 @@ import (
 var removeForce bool
 
 var removeCmd = &cobra.Command{
-	Use:     "remove <server>",
-	Aliases: []string{"rm", "delete"},
-	Short:   "Remove a server",
-	Long:    `Remove a server from the configuration.`,
-	Args:    cobra.ExactArgs(1),
+	Use:               "remove <server>",
+	Aliases:           []string{"rm", "delete"},
+	Short:             "Remove a server",
+	Long:              `Remove a server from the configuration.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeServer,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		serverName := args[0]
 
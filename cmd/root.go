@@ -1,31 +1,45 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
 	"github.com/codoworks/sshto/internal/app"
 	"github.com/codoworks/sshto/internal/config"
+	"github.com/codoworks/sshto/internal/ssh"
 )
 
 var (
 	cfgFile string
+	dryRun  bool
 	App     *app.App
 )
 
 var rootCmd = &cobra.Command{
-	Use:   "sshto [server]",
+	Use:   "sshto [server] [-- ssh args] [command]",
 	Short: "SSH connection manager with interactive menu",
 	Long: `sshto is an SSH connection manager that provides an interactive
 menu for selecting and connecting to SSH servers.
 
 Run without arguments to open the interactive server selection menu.
-Run with a server name to connect directly.`,
-	Args: cobra.MaximumNArgs(1),
+Run with a server name to connect directly, or with - to reconnect to
+the last server. Flags for sshto come before the server name; anything
+after it is passed through as with 'sshto connect': a remote command,
+and ssh arguments after --.
+
+sshto exits with the status of ssh, which is that of the remote command
+when one is given, or with 125 when sshto itself fails.`,
+	Args:              cobra.ArbitraryArgs,
+	ValidArgsFunction: completeServer,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if len(args) == 1 {
+		if len(args) == 1 && args[0] == "-" {
+			return lastCmd.RunE(cmd, nil)
+		}
+		if len(args) > 0 {
 			// Direct connection mode
 			return connectCmd.RunE(cmd, args)
 		}
@@ -42,16 +56,84 @@ var configCmd = &cobra.Command{
 	},
 }
 
+const (
+	// exitFailure is the status for a command that ran and reports a
+	// failure as its result, e.g. servers failing in exec or status
+	exitFailure = 1
+
+	// exitError is the status for sshto's own errors, kept apart from the
+	// statuses of ssh and remote commands. 125 follows env(1) and docker.
+	exitError = 125
+)
+
+// resultError marks an error as the failed result of a command rather than
+// an error in sshto, so sshto exits with exitFailure
+type resultError struct {
+	error
+}
+
+func (e resultError) Unwrap() error {
+	return e.error
+}
+
+// Execute runs the root command and exits with the status of ssh or the
+// remote command when one ran and failed, or exitError for sshto's own errors
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+	os.Exit(exitCode(rootCmd.Execute()))
+}
+
+// sessionError returns the error of an interactive ssh, scp or rsync run.
+// A non-zero exit is not reported again: the program has shown why it failed.
+func sessionError(cmd *cobra.Command, err error) error {
+	var sshErr *ssh.ExitError
+	if errors.As(err, &sshErr) {
+		cmd.SilenceErrors = true
 	}
+	return err
+}
+
+// addDryRunFlag registers --dry-run (or --print) on a command that runs ssh,
+// scp or rsync. It is never global: a command that changes state must not
+// accept a dry run it would ignore.
+func addDryRunFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the ssh, scp or rsync command instead of running it (also --print)")
+}
+
+// printCommand prints the command a --dry-run would have run
+func printCommand(command string, err error) error {
+	if err != nil {
+		return err
+	}
+	fmt.Println(command)
+	return nil
+}
+
+// exitCode returns the process status for the error a command returned
+func exitCode(err error) int {
+	var sshErr *ssh.ExitError
+	var result resultError
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &sshErr):
+		return sshErr.Code
+	case errors.As(err, &result):
+		return exitFailure
+	}
+	return exitError
 }
 
 func init() {
 	cobra.OnInitialize(initApp)
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is ~/.config/sshto/config.yaml)")
+	addDryRunFlag(rootCmd)
+	rootCmd.SetGlobalNormalizationFunc(func(f *pflag.FlagSet, name string) pflag.NormalizedName {
+		if name == "print" {
+			name = "dry-run"
+		}
+		return pflag.NormalizedName(name)
+	})
 
 	// Add subcommands
 	rootCmd.AddCommand(configCmd)
@@ -59,8 +141,17 @@ func init() {
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(addCmd)
 	rootCmd.AddCommand(editCmd)
+	rootCmd.AddCommand(showCmd)
 	rootCmd.AddCommand(removeCmd)
 	rootCmd.AddCommand(groupsCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(lastCmd)
+	rootCmd.AddCommand(execCmd)
+	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(cpCmd)
+	rootCmd.AddCommand(tunnelCmd)
 }
 
 func initApp() {
@@ -73,6 +164,6 @@ func initApp() {
 	App, err = app.New(path)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitError)
 	}
 }
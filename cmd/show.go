@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/codoworks/sshto/internal/config"
+)
+
+var showCmd = &cobra.Command{
+	Use:   "show <server> [-- ssh args] [command]",
+	Short: "Show a server's effective settings",
+	Long: `Show a server as stored in the config, the settings sshto will use for it
+after applying its group, the defaults and any overrides given as flags,
+along with the layer each value came from, and the ssh command it runs.
+
+Takes the same flags and arguments as connect, so the effect of overrides
+can be checked before connecting:
+
+  sshto show -u root -J bastion web-1 -- -A uptime`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeServer,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts, err := connectOptions()
+		if err != nil {
+			return err
+		}
+		opts.ExtraArgs, opts.Command, err = sessionArgs(cmd, args)
+		if err != nil {
+			return err
+		}
+		cmd.SilenceUsage = true
+
+		name, err := lookupServer(args[0])
+		if err != nil || name == "" {
+			return err
+		}
+		stored, err := App.Config.FindServer(name)
+		if err != nil {
+			return err
+		}
+		res, err := App.Explain(name, opts)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Server: %s\n", res.Server.Name)
+		if res.Server.Group != "" {
+			fmt.Printf("Group:  %s\n", App.Config.GroupPath(res.Server.Group))
+		}
+		if len(res.Server.Tags) > 0 {
+			fmt.Printf("Tags:   %s\n", strings.Join(res.Server.Tags, ", "))
+		}
+
+		fmt.Println("\nStored:")
+		if err := printStored(stored); err != nil {
+			return err
+		}
+		fmt.Println()
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "FIELD\tVALUE\tSOURCE")
+		for _, f := range res.Fields() {
+			value, source := f.Value, string(f.Source)
+			if value == "" {
+				value, source = "-", "-"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", f.Name, value, source)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+
+		fmt.Println("\nCommand:")
+		if res.Server.Transport == config.TransportNative {
+			fmt.Println("  none, the native transport connects without ssh")
+			return nil
+		}
+		fmt.Printf("  %s\n", App.SSHClient.BuildCommand(res.Server))
+		return nil
+	},
+}
+
+// printStored prints the server's own config entry, indented
+func printStored(s *config.Server) error {
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("marshaling server: %w", err)
+	}
+	for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+		fmt.Printf("  %s\n", line)
+	}
+	return nil
+}
+
+func init() {
+	addConnectFlags(showCmd)
+	stopFlagsAtServer(showCmd)
+}
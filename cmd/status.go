@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/codoworks/sshto/internal/app"
+	"github.com/codoworks/sshto/internal/probe"
+)
+
+var (
+	statusGroup    string
+	statusParallel int
+	statusTimeout  time.Duration
+	statusTCPOnly  bool
+	statusJSON     bool
+)
+
+var statusCmd = &cobra.Command{
+	Use:     "status [servers...]",
+	Aliases: []string{"ping"},
+	Short:   "Check which servers are reachable",
+	Long: `Check the named servers, or every server, in parallel and report each one as
+reachable, auth-failed, host-key-failed, timeout, refused, dns-failure or error.
+
+Each check dials the ssh port first (the first jump host's port for servers
+behind a jump host), then logs in without prompting to verify authentication.
+Use --tcp-only to skip the login. The exit status is non-zero when any server
+is not reachable.`,
+	ValidArgsFunction: completeServers,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts, err := connectOptions()
+		if err != nil {
+			return err
+		}
+
+		names, err := selectServers(args, statusGroup)
+		if err != nil {
+			return err
+		}
+		cmd.SilenceUsage = true
+
+		results, err := App.Status(names, app.StatusOptions{
+			Connect:  opts,
+			Parallel: statusParallel,
+			Timeout:  statusTimeout,
+			TCPOnly:  statusTCPOnly,
+		})
+		if err != nil {
+			return err
+		}
+
+		if statusJSON {
+			err = printStatusJSON(results)
+		} else {
+			err = printStatusTable(results)
+		}
+		if err != nil {
+			return err
+		}
+
+		failed := 0
+		for _, r := range results {
+			if !r.OK() {
+				failed++
+			}
+		}
+		if failed > 0 {
+			return resultError{fmt.Errorf("%d of %d servers not reachable", failed, len(results))}
+		}
+		return nil
+	},
+}
+
+func printStatusTable(results []probe.Result) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SERVER\tADDRESS\tSTATUS\tLATENCY\tDETAIL")
+	for _, r := range results {
+		latency := "-"
+		if r.OK() {
+			latency = r.Latency.Round(time.Millisecond).String()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.Server, r.Address, r.State, latency, r.Detail)
+	}
+	return w.Flush()
+}
+
+// statusRecord is the JSON form of a probe.Result
+type statusRecord struct {
+	Server    string      `json:"server"`
+	Address   string      `json:"address"`
+	State     probe.State `json:"state"`
+	LatencyMS float64     `json:"latency_ms,omitempty"`
+	Detail    string      `json:"detail,omitempty"`
+}
+
+func printStatusJSON(results []probe.Result) error {
+	out := make([]statusRecord, len(results))
+	for i, r := range results {
+		out[i] = statusRecord{
+			Server:  r.Server,
+			Address: r.Address,
+			State:   r.State,
+			Detail:  r.Detail,
+		}
+		if r.OK() {
+			out[i].LatencyMS = float64(r.Latency.Microseconds()) / 1000
+		}
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+func init() {
+	statusCmd.Flags().StringVarP(&statusGroup, "group", "g", "", "check servers in this group and its nested groups")
+	_ = statusCmd.RegisterFlagCompletionFunc("group", completeGroupFlag)
+	statusCmd.Flags().IntVarP(&statusParallel, "parallel", "P", 20, "maximum number of servers to check at once")
+	statusCmd.Flags().DurationVar(&statusTimeout, "timeout", 5*time.Second, "timeout for each check")
+	statusCmd.Flags().BoolVar(&statusTCPOnly, "tcp-only", false, "only dial the ssh port, skip the login")
+	statusCmd.Flags().BoolVar(&statusJSON, "json", false, "print results as JSON")
+	addTagFlags(statusCmd)
+	addOverrideFlags(statusCmd)
+}
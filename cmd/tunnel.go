@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/codoworks/sshto/internal/config"
+	"github.com/codoworks/sshto/internal/tunnel"
+)
+
+var (
+	tunnelTimeout time.Duration
+	tunnelStopAll bool
+)
+
+var tunnelCmd = &cobra.Command{
+	Use:   "tunnel",
+	Short: "Manage background tunnels",
+	Long: `Keep a server's port forwards open in the background with a forward-only
+ssh connection, without a shell. sshto records each tunnel's process so it
+can be listed and stopped later; tunnels whose process has died are cleaned
+up automatically.
+
+Run without a subcommand to list the running tunnels.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return tunnelLsCmd.RunE(cmd, args)
+	},
+}
+
+var tunnelStartCmd = &cobra.Command{
+	Use:   "start <server>",
+	Short: "Start a tunnel for a server's forwards",
+	Long: `Start a background tunnel holding the server's configured forwards open.
+Use --forward to add forwards, and --no-forwards to open only those.
+
+The tunnel never prompts, so the server must accept a key or agent login.
+sshto waits for the local ports to accept connections before returning.
+ssh's output goes to a log file in the tunnel state directory.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeServer,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts, err := connectOptions()
+		if err != nil {
+			return err
+		}
+		cmd.SilenceUsage = true
+
+		name, err := lookupServer(args[0])
+		if err != nil || name == "" {
+			return err
+		}
+
+		if dryRun {
+			return printCommand(App.TunnelCommand(name, opts))
+		}
+		t, err := App.StartTunnel(name, opts, tunnelTimeout)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Started tunnel to %s (pid %d)\n", t.Server, t.PID)
+		for _, f := range t.Forwards {
+			fmt.Printf("  %s\n", f)
+		}
+		if t.Check(time.Second) != tunnel.HealthUp {
+			fmt.Printf("Local ports are not answering yet, see %s\n", t.Log)
+		}
+		return nil
+	},
+}
+
+var tunnelStopCmd = &cobra.Command{
+	Use:               "stop <server>...",
+	Short:             "Stop tunnels",
+	Long:              `Stop the tunnels to the named servers, or every tunnel with --all.`,
+	ValidArgsFunction: completeTunnels,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if tunnelStopAll == (len(args) > 0) {
+			return fmt.Errorf("give server names or --all")
+		}
+		cmd.SilenceUsage = true
+
+		names := args
+		if tunnelStopAll {
+			tunnels, err := App.Tunnels.List()
+			if err != nil {
+				return err
+			}
+			if len(tunnels) == 0 {
+				fmt.Println("No tunnels running.")
+				return nil
+			}
+			for _, t := range tunnels {
+				names = append(names, t.Server)
+			}
+		}
+
+		for _, name := range names {
+			t, err := App.StopTunnel(name)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Stopped tunnel to %s (pid %d)\n", t.Server, t.PID)
+		}
+		return nil
+	},
+}
+
+var tunnelLsCmd = &cobra.Command{
+	Use:     "ls",
+	Aliases: []string{"list"},
+	Short:   "List running tunnels",
+	Long: `List the running tunnels with their forwards, uptime and health.
+A tunnel is up when every local port accepts connections and degraded when
+its process is running but a local port does not answer.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tunnels, err := App.Tunnels.List()
+		if err != nil {
+			return err
+		}
+		if len(tunnels) == 0 {
+			fmt.Println("No tunnels running.")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "SERVER\tPID\tFORWARDS\tUPTIME\tHEALTH")
+		for _, t := range tunnels {
+			uptime := time.Since(t.Started).Round(time.Second)
+			fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\n", t.Server, t.PID, forwardList(t.Forwards), uptime, t.Check(time.Second))
+		}
+		return w.Flush()
+	},
+}
+
+// forwardList formats forwards as a comma separated list of specs
+func forwardList(forwards []config.Forward) string {
+	specs := make([]string, len(forwards))
+	for i, f := range forwards {
+		specs[i] = f.String()
+	}
+	return strings.Join(specs, ", ")
+}
+
+func init() {
+	tunnelStartCmd.Flags().DurationVar(&tunnelTimeout, "timeout", 15*time.Second, "how long to wait for the local ports to open")
+	addConnectFlags(tunnelStartCmd)
+	addDryRunFlag(tunnelStartCmd)
+
+	tunnelStopCmd.Flags().BoolVar(&tunnelStopAll, "all", false, "stop every tunnel")
+
+	tunnelCmd.AddCommand(tunnelStartCmd)
+	tunnelCmd.AddCommand(tunnelStopCmd)
+	tunnelCmd.AddCommand(tunnelLsCmd)
+}
@@ -1,14 +1,22 @@
 package app
 
 import (
+	"fmt"
+	"os"
+	"time"
+
 	"github.com/codoworks/sshto/internal/config"
+	"github.com/codoworks/sshto/internal/history"
 	"github.com/codoworks/sshto/internal/ssh"
+	"github.com/codoworks/sshto/internal/tunnel"
 )
 
 // App orchestrates the sshto application
 type App struct {
 	Config    *config.Config
 	SSHClient *ssh.Client
+	History   *history.Store // nil disables connection history
+	Tunnels   *tunnel.Store
 }
 
 // New creates a new App instance
@@ -18,55 +26,112 @@ func New(configPath string) (*App, error) {
 		return nil, err
 	}
 
+	// History is best effort: a damaged file is reported and started afresh
+	hist, err := history.Load(history.Path(cfg.Path()))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring connection history: %v\n", err)
+		hist = history.New(history.Path(cfg.Path()))
+	}
+
+	// Forget tunnels whose ssh process has gone away since the last run
+	tunnels := tunnel.NewStore(tunnel.Dir(cfg.Path()))
+	_, _ = tunnels.Prune()
+
+	client := ssh.NewClient()
+	client.SSHBinary = cfg.SSHBinary
+	client.SCPBinary = cfg.SCPBinary
+
 	return &App{
 		Config:    cfg,
-		SSHClient: ssh.NewClient(),
+		SSHClient: client,
+		History:   hist,
+		Tunnels:   tunnels,
 	}, nil
 }
 
 // Connect establishes an SSH connection to the named server with optional overrides
 func (a *App) Connect(serverName string, opts ssh.ConnectOptions) error {
-	server, err := a.Config.FindServer(serverName)
+	resolved, err := a.Resolve(serverName, opts)
 	if err != nil {
 		return err
 	}
 
-	// Apply defaults
-	resolved := a.resolveServer(server)
+	start := time.Now()
+	a.remember(resolved.Name, start, opts)
+	err = a.SSHClient.Connect(resolved)
+	a.record(resolved.Name, start, err)
+	return err
+}
 
-	// Apply overrides
-	if opts.User != "" {
-		resolved.User = opts.User
+// LastConnection returns the most recent connection, merging overrides on top
+// of the ones it was made with
+func (a *App) LastConnection(overrides ssh.ConnectOptions) (string, ssh.ConnectOptions, error) {
+	if a.History == nil || a.History.Last == nil {
+		return "", overrides, fmt.Errorf("no previous connection")
 	}
-	if opts.Port != 0 {
-		resolved.Port = opts.Port
+	last := a.History.Last
+	return last.Server, last.Overrides.Merge(overrides), nil
+}
+
+// remember saves the connection about to be made before ssh starts,
+// so it can be replayed even if sshto does not exit cleanly. Only the
+// overrides are kept: replaying reopens the session, without running the
+// remote command again.
+func (a *App) remember(serverName string, start time.Time, opts ssh.ConnectOptions) {
+	if a.History == nil {
+		return
 	}
-	if opts.Key != "" {
-		resolved.Key = opts.Key
+	opts.ExtraArgs, opts.Command = nil, ""
+	a.updateHistory(func(s *history.Store) {
+		s.Last = &history.Last{Server: serverName, Time: start, Overrides: opts}
+	})
+}
+
+// record adds a finished connection to the history. History is best effort:
+// failing to save it must not change the outcome of the session.
+func (a *App) record(serverName string, start time.Time, err error) {
+	code, ran := ssh.ExitStatus(err)
+	if a.History == nil || !ran {
+		return
 	}
 
-	return a.SSHClient.Connect(resolved)
+	entry := history.Entry{
+		Server:   serverName,
+		Time:     start,
+		Duration: time.Since(start).Round(time.Second),
+		ExitCode: code,
+	}
+	a.updateHistory(func(s *history.Store) { s.Add(entry) })
 }
 
-// resolveServer applies defaults to a server config
-func (a *App) resolveServer(s *config.Server) *config.Server {
-	resolved := *s
-
-	if resolved.User == "" && a.Config.Defaults.User != "" {
-		resolved.User = a.Config.Defaults.User
+// updateHistory applies fn to the history file as it is now, which other
+// sessions may have written to since sshto started
+func (a *App) updateHistory(fn func(*history.Store)) {
+	if s, err := history.Update(a.History.Path(), fn); err == nil {
+		*a.History = *s
 	}
-	if resolved.Port == 0 {
-		if a.Config.Defaults.Port != 0 {
-			resolved.Port = a.Config.Defaults.Port
-		} else {
-			resolved.Port = 22
-		}
+}
+
+// Scores returns the frecency score of each server in the history
+func (a *App) Scores() map[string]float64 {
+	if a.History == nil {
+		return nil
 	}
-	if resolved.Key == "" && a.Config.Defaults.Key != "" {
-		resolved.Key = a.Config.Defaults.Key
+	return a.History.Scores(time.Now())
+}
+
+// Resolve returns the named server with defaults and overrides applied
+func (a *App) Resolve(serverName string, opts ssh.ConnectOptions) (*config.Server, error) {
+	res, err := a.Explain(serverName, opts)
+	if err != nil {
+		return nil, err
 	}
+	return res.Server, nil
+}
 
-	return &resolved
+// resolveServer applies group settings and defaults to a server config
+func (a *App) resolveServer(s *config.Server) *config.Server {
+	return a.explainServer(s, ssh.ConnectOptions{}).Server
 }
 
 // Save persists the config to disk
@@ -1,14 +1,32 @@
 package app
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/codoworks/sshto/internal/config"
+	"github.com/codoworks/sshto/internal/history"
 	"github.com/codoworks/sshto/internal/ssh"
 )
 
+// newTestApp returns an app for the given servers and defaults, with no
+// history or tunnels, that runs ssh through fakeSSH. The servers are copied,
+// so tests may share them.
+func newTestApp(defaults config.Defaults, servers ...config.Server) *App {
+	return &App{
+		Config: &config.Config{
+			Servers:  slices.Clone(servers),
+			Defaults: defaults,
+		},
+		SSHClient: &ssh.Client{Runner: fakeSSH{}},
+	}
+}
+
 func TestNew(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "sshto-test")
 	if err != nil {
@@ -32,6 +50,9 @@ func TestNew(t *testing.T) {
 	if app.SSHClient == nil {
 		t.Error("App.SSHClient is nil")
 	}
+	if app.History == nil {
+		t.Error("App.History is nil")
+	}
 }
 
 func TestNewWithExistingConfig(t *testing.T) {
@@ -52,6 +73,7 @@ func TestNewWithExistingConfig(t *testing.T) {
 defaults:
   user: deploy
   port: 2222
+ssh_binary: /opt/corp/bin/ssh
 `
 	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
 		t.Fatalf("Failed to write config: %v", err)
@@ -67,6 +89,30 @@ defaults:
 	if app.Config.Servers[0].Name != "test-server" {
 		t.Errorf("Server name = %q, want %q", app.Config.Servers[0].Name, "test-server")
 	}
+	if app.SSHClient.SSHBinary != "/opt/corp/bin/ssh" || app.SSHClient.SCPBinary != "" {
+		t.Errorf("SSHClient = %+v, want the configured ssh binary", app.SSHClient)
+	}
+}
+
+func TestNewWithDamagedHistory(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sshto-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(history.Path(configPath), []byte("entries: [\n  - server: web"), 0644); err != nil {
+		t.Fatalf("Failed to write history: %v", err)
+	}
+
+	app, err := New(configPath)
+	if err != nil {
+		t.Fatalf("New() error = %v, want a damaged history ignored", err)
+	}
+	if app.History == nil || len(app.History.Entries) != 0 {
+		t.Errorf("History = %+v, want an empty history", app.History)
+	}
 }
 
 func TestResolveServer(t *testing.T) {
@@ -235,3 +281,236 @@ func TestConnectResolution(t *testing.T) {
 		t.Errorf("Override key = %q, want %q", resolved.Key, "/tmp/key")
 	}
 }
+
+func TestResolve(t *testing.T) {
+	app := &App{
+		Config: &config.Config{
+			Servers: []config.Server{
+				{Name: "test", Host: "localhost", User: "original"},
+			},
+			Defaults: config.Defaults{Port: 2222, Key: "~/.ssh/default_key"},
+		},
+		SSHClient: ssh.NewClient(),
+	}
+
+	resolved, err := app.Resolve("test", ssh.ConnectOptions{User: "override", Key: "/tmp/key"})
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if resolved.User != "override" {
+		t.Errorf("User = %q, want %q", resolved.User, "override")
+	}
+	if resolved.Port != 2222 {
+		t.Errorf("Port = %d, want %d", resolved.Port, 2222)
+	}
+	if resolved.Key != "/tmp/key" {
+		t.Errorf("Key = %q, want %q", resolved.Key, "/tmp/key")
+	}
+
+	// The stored server must not be modified
+	if app.Config.Servers[0].User != "original" {
+		t.Errorf("Stored user = %q, want %q", app.Config.Servers[0].User, "original")
+	}
+
+	if _, err := app.Resolve("nonexistent", ssh.ConnectOptions{}); err == nil {
+		t.Error("Resolve() should return error for non-existent server")
+	}
+}
+
+func TestResolveForwards(t *testing.T) {
+	local := config.Forward{Type: config.ForwardLocal, Listen: "5432", Target: "localhost:5432"}
+	dynamic := config.Forward{Type: config.ForwardDynamic, Listen: "1080"}
+
+	app := &App{
+		Config: &config.Config{
+			Servers: []config.Server{
+				{Name: "db", Host: "10.0.0.5", Forwards: []config.Forward{local}},
+			},
+		},
+		SSHClient: ssh.NewClient(),
+	}
+
+	resolved, err := app.Resolve("db", ssh.ConnectOptions{Forwards: []config.Forward{dynamic}})
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if len(resolved.Forwards) != 2 || resolved.Forwards[0] != local || resolved.Forwards[1] != dynamic {
+		t.Errorf("Forwards = %+v, want configured plus extra", resolved.Forwards)
+	}
+	if len(app.Config.Servers[0].Forwards) != 1 {
+		t.Error("Resolve() must not modify the stored forwards")
+	}
+
+	resolved, err = app.Resolve("db", ssh.ConnectOptions{NoForwards: true})
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if len(resolved.Forwards) != 0 {
+		t.Errorf("Forwards = %+v, want none with NoForwards", resolved.Forwards)
+	}
+}
+
+func TestResolveOptions(t *testing.T) {
+	app := &App{
+		Config: &config.Config{
+			Groups: []config.Group{
+				{Name: "production", Options: map[string]string{"ForwardAgent": "no", "ServerAliveInterval": "15"}},
+			},
+			Servers: []config.Server{
+				{Name: "web", Host: "10.0.0.1", Group: "production", Options: map[string]string{"serveraliveinterval": "5"}},
+			},
+			Defaults: config.Defaults{Options: map[string]string{"ForwardAgent": "yes", "Compression": "yes"}},
+		},
+		SSHClient: ssh.NewClient(),
+	}
+
+	resolved, err := app.Resolve("web", ssh.ConnectOptions{Options: map[string]string{"compression": "no"}})
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+
+	expected := map[string]string{
+		"ForwardAgent":        "no", // group beats defaults
+		"serveraliveinterval": "5",  // server beats group
+		"compression":         "no", // command line beats everything
+	}
+	if len(resolved.Options) != len(expected) {
+		t.Fatalf("Options = %v, want %v", resolved.Options, expected)
+	}
+	for key, value := range expected {
+		if resolved.Options[key] != value {
+			t.Errorf("Options[%s] = %q, want %q", key, resolved.Options[key], value)
+		}
+	}
+}
+
+// fakeRunner records the ssh commands it is asked to run and fails them with err
+type fakeRunner struct {
+	commands []*ssh.Command
+	err      error
+}
+
+func (r *fakeRunner) Run(cmd *ssh.Command) error {
+	r.commands = append(r.commands, cmd)
+	return r.err
+}
+
+func (r *fakeRunner) Start(cmd *ssh.Command) (*ssh.Process, error) {
+	r.commands = append(r.commands, cmd)
+	return nil, r.err
+}
+
+func TestConnectWithRunner(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sshto-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	hist, err := history.Load(filepath.Join(tmpDir, "history.yaml"))
+	if err != nil {
+		t.Fatalf("history.Load() error = %v", err)
+	}
+	runner := &fakeRunner{err: &ssh.ExitError{Program: "ssh", Code: 3}}
+	app := &App{
+		Config: &config.Config{
+			Servers: []config.Server{{Name: "web", Host: "10.0.0.1", User: "deploy"}},
+		},
+		SSHClient: &ssh.Client{Runner: runner},
+		History:   hist,
+	}
+
+	err = app.Connect("web", ssh.ConnectOptions{Port: 2222, Command: "test -f /x"})
+	if code, _ := ssh.ExitStatus(err); code != 3 {
+		t.Errorf("Connect() error = %v, want exit status 3", err)
+	}
+
+	if len(runner.commands) != 1 {
+		t.Fatalf("ran %d commands, want 1", len(runner.commands))
+	}
+	// -t may come between the two, depending on whether the test runs in a terminal
+	got := strings.Join(runner.commands[0].Args, " ")
+	if !strings.HasPrefix(got, "-p 2222 ") || !strings.HasSuffix(got, "-- deploy@10.0.0.1 test -f /x") {
+		t.Errorf("ssh args = %q", got)
+	}
+
+	if len(hist.Entries) != 1 || hist.Entries[0].ExitCode != 3 {
+		t.Errorf("history = %+v, want one entry with exit code 3", hist.Entries)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sshto-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	path := history.Path(filepath.Join(tmpDir, "config.yaml"))
+	hist, err := history.Load(path)
+	if err != nil {
+		t.Fatalf("history.Load() error = %v", err)
+	}
+	app := &App{Config: &config.Config{}, History: hist}
+
+	app.record("web1", time.Now().Add(-time.Minute), nil)
+	// ssh never started, nothing to record
+	app.record("web1", time.Now(), errors.New("exec: \"ssh\": executable file not found"))
+
+	saved, err := history.Load(path)
+	if err != nil {
+		t.Fatalf("history.Load() error = %v", err)
+	}
+	if len(saved.Entries) != 1 {
+		t.Fatalf("Entries = %d, want 1", len(saved.Entries))
+	}
+	if e := saved.Entries[0]; e.Server != "web1" || e.ExitCode != 0 || e.Duration != time.Minute {
+		t.Errorf("Entries[0] = %+v", e)
+	}
+	if app.Scores()["web1"] == 0 {
+		t.Error("Scores() should rank a recently used server")
+	}
+
+	// Without a history nothing is recorded
+	(&App{}).record("web1", time.Now(), nil)
+}
+
+func TestLastConnection(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sshto-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	hist, err := history.Load(history.Path(filepath.Join(tmpDir, "config.yaml")))
+	if err != nil {
+		t.Fatalf("history.Load() error = %v", err)
+	}
+	app := &App{Config: &config.Config{}, History: hist}
+
+	if _, _, err := app.LastConnection(ssh.ConnectOptions{}); err == nil {
+		t.Error("LastConnection() should return error without a previous connection")
+	}
+
+	app.remember("web1", time.Now(), ssh.ConnectOptions{User: "root", Port: 2222, ExtraArgs: []string{"-A"}, Command: "rm -rf /tmp/x"})
+
+	name, opts, err := app.LastConnection(ssh.ConnectOptions{Port: 2200})
+	if err != nil {
+		t.Fatalf("LastConnection() error = %v", err)
+	}
+	if name != "web1" || opts.User != "root" || opts.Port != 2200 {
+		t.Errorf("LastConnection() = %q, %+v, want web1 as root on port 2200", name, opts)
+	}
+	// The remote command and ssh arguments are not replayed
+	if opts.Command != "" || opts.ExtraArgs != nil {
+		t.Errorf("LastConnection() = %+v, want no command or ssh arguments", opts)
+	}
+
+	saved, err := history.Load(hist.Path())
+	if err != nil {
+		t.Fatalf("history.Load() error = %v", err)
+	}
+	if saved.Last == nil || saved.Last.Server != "web1" {
+		t.Errorf("saved Last = %+v, want web1", saved.Last)
+	}
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/codoworks/sshto/internal/config"
+	"github.com/codoworks/sshto/internal/ssh"
+)
+
+// CopyTarget is one side of a copy as given on the command line
+type CopyTarget struct {
+	Server string // empty for local paths
+	Path   string
+}
+
+// ParseCopyTarget splits a server:path argument. The part before the first
+// colon must name a configured server. Paths with a slash before the colon
+// and single letter prefixes (Windows drives) are always local.
+func (a *App) ParseCopyTarget(arg string) (CopyTarget, error) {
+	prefix, path, ok := strings.Cut(arg, ":")
+	if !ok || len(prefix) < 2 || strings.ContainsAny(prefix, `/\`) {
+		return CopyTarget{Path: arg}, nil
+	}
+	if _, err := a.Config.FindServer(prefix); err != nil {
+		return CopyTarget{}, fmt.Errorf("%w (write local paths containing ':' as ./%s)", err, arg)
+	}
+	return CopyTarget{Server: prefix, Path: path}, nil
+}
+
+// Copy transfers sources to dest with scp or rsync. Exactly one side must be
+// on a server, and it is reached with the server's resolved settings.
+func (a *App) Copy(sources []string, dest string, connect ssh.ConnectOptions, opts ssh.CopyOptions) error {
+	server, srcs, dst, err := a.planCopy(sources, dest, connect)
+	if err != nil {
+		return err
+	}
+	return a.SSHClient.Copy(server, srcs, dst, opts)
+}
+
+// planCopy resolves the server a copy involves and the locations on each side
+func (a *App) planCopy(sources []string, dest string, connect ssh.ConnectOptions) (*config.Server, []ssh.Location, ssh.Location, error) {
+	var server string
+	toLocation := func(arg string) (ssh.Location, error) {
+		t, err := a.ParseCopyTarget(arg)
+		if err != nil {
+			return ssh.Location{}, err
+		}
+		if t.Server == "" {
+			return ssh.Location{Path: t.Path}, nil
+		}
+		if server != "" && server != t.Server {
+			return ssh.Location{}, fmt.Errorf("cannot copy between servers %q and %q", server, t.Server)
+		}
+		server = t.Server
+		return ssh.Location{Path: t.Path, Remote: true}, nil
+	}
+
+	srcs := make([]ssh.Location, len(sources))
+	remoteSources := 0
+	for i, arg := range sources {
+		loc, err := toLocation(arg)
+		if err != nil {
+			return nil, nil, ssh.Location{}, err
+		}
+		if loc.Remote {
+			remoteSources++
+		}
+		srcs[i] = loc
+	}
+	dst, err := toLocation(dest)
+	if err != nil {
+		return nil, nil, ssh.Location{}, err
+	}
+
+	switch {
+	case server == "":
+		return nil, nil, ssh.Location{}, fmt.Errorf("neither side is on a server: use server:path for the remote side")
+	case dst.Remote && remoteSources > 0:
+		return nil, nil, ssh.Location{}, fmt.Errorf("only one side of a copy can be on a server")
+	case !dst.Remote && remoteSources != len(srcs):
+		return nil, nil, ssh.Location{}, fmt.Errorf("sources must all be local or all on the server")
+	}
+
+	resolved, err := a.Resolve(server, connect)
+	if err != nil {
+		return nil, nil, ssh.Location{}, err
+	}
+	return resolved, srcs, dst, nil
+}
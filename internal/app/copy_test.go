@@ -0,0 +1,91 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/codoworks/sshto/internal/config"
+	"github.com/codoworks/sshto/internal/ssh"
+)
+
+// copyServers are the servers the copy tests copy to and from
+var copyServers = []config.Server{
+	{Name: "web", Host: "10.0.0.1", User: "deploy"},
+	{Name: "db", Host: "10.0.0.2"},
+}
+
+func TestParseCopyTarget(t *testing.T) {
+	app := newTestApp(config.Defaults{}, copyServers...)
+
+	tests := []struct {
+		arg     string
+		want    CopyTarget
+		wantErr bool
+	}{
+		{"web:/etc/hosts", CopyTarget{Server: "web", Path: "/etc/hosts"}, false},
+		{"web:", CopyTarget{Server: "web", Path: ""}, false},
+		{"notes.txt", CopyTarget{Path: "notes.txt"}, false},
+		{"./web:file", CopyTarget{Path: "./web:file"}, false},
+		{"/tmp/a:b", CopyTarget{Path: "/tmp/a:b"}, false},
+		{`C:\Users\me\file`, CopyTarget{Path: `C:\Users\me\file`}, false},
+		{"unknown:/etc/hosts", CopyTarget{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			got, err := app.ParseCopyTarget(tt.arg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCopyTarget(%q) error = %v, wantErr %v", tt.arg, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseCopyTarget(%q) = %+v, want %+v", tt.arg, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanCopy(t *testing.T) {
+	app := newTestApp(config.Defaults{}, copyServers...)
+
+	server, srcs, dst, err := app.planCopy([]string{"a.txt", "b.txt"}, "web:/srv/", ssh.ConnectOptions{User: "root"})
+	if err != nil {
+		t.Fatalf("planCopy() error = %v", err)
+	}
+	if server.Name != "web" || server.User != "root" {
+		t.Errorf("server = %+v, want web resolved with the user override", server)
+	}
+	if len(srcs) != 2 || srcs[0].Remote || !dst.Remote || dst.Path != "/srv/" {
+		t.Errorf("srcs = %+v, dst = %+v", srcs, dst)
+	}
+
+	server, srcs, dst, err = app.planCopy([]string{"db:/var/log/a", "db:/var/log/b"}, ".", ssh.ConnectOptions{})
+	if err != nil {
+		t.Fatalf("planCopy() error = %v", err)
+	}
+	if server.Name != "db" || !srcs[1].Remote || dst.Remote {
+		t.Errorf("download plan = %+v, %+v, %+v", server, srcs, dst)
+	}
+}
+
+func TestPlanCopyErrors(t *testing.T) {
+	app := newTestApp(config.Defaults{}, copyServers...)
+
+	tests := []struct {
+		name    string
+		sources []string
+		dest    string
+	}{
+		{"both local", []string{"a.txt"}, "b.txt"},
+		{"both remote", []string{"web:a"}, "web:b"},
+		{"two servers", []string{"web:a", "db:b"}, "."},
+		{"mixed sources", []string{"web:a", "b.txt"}, "."},
+		{"unknown server", []string{"a.txt"}, "nope:/tmp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, _, err := app.planCopy(tt.sources, tt.dest, ssh.ConnectOptions{}); err == nil {
+				t.Errorf("planCopy(%v, %q) should return error", tt.sources, tt.dest)
+			}
+		})
+	}
+}
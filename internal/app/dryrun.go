@@ -0,0 +1,72 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/codoworks/sshto/internal/config"
+	"github.com/codoworks/sshto/internal/ssh"
+)
+
+// The methods below return the shell-quoted commands that Connect, Exec,
+// Copy and StartTunnel would run, without running them or recording history
+
+// ConnectCommand returns the ssh command Connect runs for the named server
+func (a *App) ConnectCommand(serverName string, opts ssh.ConnectOptions) (string, error) {
+	resolved, err := a.Resolve(serverName, opts)
+	if err != nil {
+		return "", err
+	}
+	if err := printable(resolved); err != nil {
+		return "", err
+	}
+	return a.SSHClient.BuildCommand(resolved), nil
+}
+
+// ExecCommands returns the ssh command Exec runs on each named server, in
+// the order of names
+func (a *App) ExecCommands(names []string, command string, opts ssh.ConnectOptions) ([]string, error) {
+	commands := make([]string, len(names))
+	for i, name := range names {
+		resolved, err := a.Resolve(name, opts)
+		if err != nil {
+			return nil, err
+		}
+		if err := printable(resolved); err != nil {
+			return nil, err
+		}
+		commands[i] = a.SSHClient.BuildRunCommand(resolved, command)
+	}
+	return commands, nil
+}
+
+// CopyCommand returns the scp or rsync command Copy runs
+func (a *App) CopyCommand(sources []string, dest string, connect ssh.ConnectOptions, opts ssh.CopyOptions) (string, error) {
+	server, srcs, dst, err := a.planCopy(sources, dest, connect)
+	if err != nil {
+		return "", err
+	}
+	if err := printable(server); err != nil {
+		return "", err
+	}
+	return a.SSHClient.BuildCopyCommand(server, srcs, dst, opts), nil
+}
+
+// TunnelCommand returns the ssh command StartTunnel runs for the named server
+func (a *App) TunnelCommand(name string, opts ssh.ConnectOptions) (string, error) {
+	resolved, err := a.resolveTunnel(name, opts)
+	if err != nil {
+		return "", err
+	}
+	if err := printable(resolved); err != nil {
+		return "", err
+	}
+	return a.SSHClient.BuildTunnelCommand(resolved), nil
+}
+
+// printable fails for servers on the native transport, which runs no command
+func printable(s *config.Server) error {
+	if s.Transport == config.TransportNative {
+		return fmt.Errorf("server %q uses the %s transport, which runs no command to print", s.Name, config.TransportNative)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/codoworks/sshto/internal/config"
+	"github.com/codoworks/sshto/internal/ssh"
+)
+
+// dryRunServers cover an openssh server, one with forwards and a native one
+var dryRunServers = []config.Server{
+	{Name: "web", Host: "10.0.0.1", User: "deploy", Port: 2222},
+	{Name: "db", Host: "10.0.0.2", Forwards: []config.Forward{{Type: config.ForwardLocal, Listen: "5432", Target: "localhost:5432"}}},
+	{Name: "native", Host: "10.0.0.3", Transport: config.TransportNative, Forwards: []config.Forward{{Type: config.ForwardLocal, Listen: "5432", Target: "localhost:5432"}}},
+}
+
+func TestDryRunCommands(t *testing.T) {
+	app := newTestApp(config.Defaults{}, dryRunServers...)
+
+	got, err := app.ConnectCommand("web", ssh.ConnectOptions{User: "root", Command: "uptime -p"})
+	if err != nil {
+		t.Fatalf("ConnectCommand() error = %v", err)
+	}
+	if !strings.HasPrefix(got, "ssh -p 2222 ") || !strings.HasSuffix(got, " -- root@10.0.0.1 'uptime -p'") {
+		t.Errorf("ConnectCommand() = %q", got)
+	}
+
+	commands, err := app.ExecCommands([]string{"web", "db"}, "df -h", ssh.ConnectOptions{})
+	if err != nil {
+		t.Fatalf("ExecCommands() error = %v", err)
+	}
+	want := []string{
+		"ssh -p 2222 -o BatchMode=yes -o ConnectTimeout=10 -- deploy@10.0.0.1 'df -h'",
+		"ssh -o BatchMode=yes -o ConnectTimeout=10 -- 10.0.0.2 'df -h'",
+	}
+	if len(commands) != 2 || commands[0] != want[0] || commands[1] != want[1] {
+		t.Errorf("ExecCommands() = %q, want %q", commands, want)
+	}
+
+	got, err = app.CopyCommand([]string{"my file"}, "web:/tmp/", ssh.ConnectOptions{}, ssh.CopyOptions{})
+	if err != nil {
+		t.Fatalf("CopyCommand() error = %v", err)
+	}
+	if got != "scp -P 2222 'my file' deploy@10.0.0.1:/tmp/" {
+		t.Errorf("CopyCommand() = %q", got)
+	}
+
+	got, err = app.TunnelCommand("db", ssh.ConnectOptions{})
+	if err != nil {
+		t.Fatalf("TunnelCommand() error = %v", err)
+	}
+	if !strings.HasPrefix(got, "ssh -N ") || !strings.Contains(got, " -L 5432:localhost:5432 ") {
+		t.Errorf("TunnelCommand() = %q", got)
+	}
+}
+
+func TestDryRunErrors(t *testing.T) {
+	app := newTestApp(config.Defaults{}, dryRunServers...)
+
+	if _, err := app.ConnectCommand("native", ssh.ConnectOptions{NoForwards: true}); err == nil || !strings.Contains(err.Error(), "native") {
+		t.Errorf("ConnectCommand(native) error = %v, want a native transport error", err)
+	}
+	if _, err := app.ExecCommands([]string{"web", "native"}, "true", ssh.ConnectOptions{}); err == nil {
+		t.Error("ExecCommands() with a native server expected error, got nil")
+	}
+	if _, err := app.TunnelCommand("web", ssh.ConnectOptions{}); err == nil || !strings.Contains(err.Error(), "no forwards") {
+		t.Errorf("TunnelCommand(web) error = %v, want no forwards", err)
+	}
+	if _, err := app.ConnectCommand("missing", ssh.ConnectOptions{}); err == nil {
+		t.Error("ConnectCommand(missing) expected error, got nil")
+	}
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/codoworks/sshto/internal/config"
+	"github.com/codoworks/sshto/internal/ssh"
+)
+
+// ExecResult is the outcome of running a command on one server
+type ExecResult struct {
+	Server   string
+	ExitCode int // -1 when ssh could not be run
+	Duration time.Duration
+	Err      error // nil when the command exited zero
+}
+
+// ExecOptions controls how Exec runs a command
+type ExecOptions struct {
+	Connect  ssh.ConnectOptions
+	Parallel int // maximum concurrent sessions, 1 if unset
+
+	// Output returns the writers for a server's stdout and stderr.
+	// Output is discarded when it is nil.
+	Output func(server string) (stdout, stderr io.Writer)
+
+	// Done is called as each server finishes, possibly concurrently
+	Done func(ExecResult)
+}
+
+// Exec runs command on each named server, resolving them like Connect does.
+// Every server is resolved before anything runs, so a typo aborts the whole
+// run. Results are returned in the order of names.
+func (a *App) Exec(names []string, command string, opts ExecOptions) ([]ExecResult, error) {
+	servers := make([]*config.Server, len(names))
+	for i, name := range names {
+		s, err := a.Resolve(name, opts.Connect)
+		if err != nil {
+			return nil, err
+		}
+		servers[i] = s
+	}
+
+	results := make([]ExecResult, len(servers))
+	parallelize(len(servers), opts.Parallel, func(i int) {
+		results[i] = a.execOne(servers[i], command, opts)
+		if opts.Done != nil {
+			opts.Done(results[i])
+		}
+	})
+
+	return results, nil
+}
+
+// parallelize calls fn for 0 through n-1 with at most limit calls running at
+// once, and returns when all calls have finished
+func parallelize(n, limit int, fn func(i int)) {
+	sem := make(chan struct{}, max(limit, 1))
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// execOne runs command on a single resolved server
+func (a *App) execOne(s *config.Server, command string, opts ExecOptions) ExecResult {
+	stdout, stderr := io.Discard, io.Discard
+	if opts.Output != nil {
+		stdout, stderr = opts.Output(s.Name)
+	}
+
+	start := time.Now()
+	err := a.SSHClient.Run(s, command, stdout, stderr)
+	result := ExecResult{Server: s.Name, Duration: time.Since(start), Err: err}
+	if code, ran := ssh.ExitStatus(err); ran {
+		result.ExitCode = code
+	} else {
+		result.ExitCode = -1
+	}
+	return result
+}
@@ -0,0 +1,125 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/codoworks/sshto/internal/config"
+	"github.com/codoworks/sshto/internal/ssh"
+)
+
+// fakeSSH is the Runner of test apps. It stands in for the ssh binary: it
+// echoes the remote command, fails for hosts or users containing "bad" and
+// keeps forward-only (-N) connections up in a helper process.
+type fakeSSH struct{}
+
+func (fakeSSH) Run(cmd *ssh.Command) error {
+	if strings.Contains(strings.Join(cmd.Args, " "), "bad") {
+		fmt.Fprintln(cmd.Stderr, "bad: Permission denied (publickey).")
+		return &ssh.ExitError{Program: "ssh", Code: 255}
+	}
+	fmt.Fprintf(cmd.Stdout, "ran %s\n", cmd.Args[len(cmd.Args)-1])
+	return nil
+}
+
+func (fakeSSH) Start(cmd *ssh.Command) (*ssh.Process, error) {
+	mode := "tunnel"
+	if strings.Contains(strings.Join(cmd.Args, " "), "bad") {
+		mode = "denied"
+	}
+	proc := exec.Command(os.Args[0], "-test.run=^TestFakeSSHProcess$")
+	proc.Env = append(os.Environ(), "SSHTO_FAKE_SSH="+mode)
+	proc.Stdout, proc.Stderr = cmd.Stdout, cmd.Stderr
+	if err := proc.Start(); err != nil {
+		return nil, err
+	}
+	return &ssh.Process{
+		PID: proc.Process.Pid,
+		Wait: func() error {
+			if err := proc.Wait(); err != nil {
+				return &ssh.ExitError{Program: "ssh", Code: proc.ProcessState.ExitCode()}
+			}
+			return nil
+		},
+	}, nil
+}
+
+// TestFakeSSHProcess is the process fakeSSH starts for tunnels, run from the
+// test binary
+func TestFakeSSHProcess(t *testing.T) {
+	switch os.Getenv("SSHTO_FAKE_SSH") {
+	case "denied":
+		fmt.Fprintln(os.Stderr, "bad: Permission denied (publickey).")
+		os.Exit(255)
+	case "tunnel":
+		time.Sleep(30 * time.Second)
+		os.Exit(0)
+	}
+}
+
+// execServers are the targets of the exec tests; broken fails with fakeSSH
+var execServers = []config.Server{
+	{Name: "web1", Host: "10.0.0.1"},
+	{Name: "web2", Host: "10.0.0.2"},
+	{Name: "broken", Host: "bad.example.com"},
+}
+
+func TestExec(t *testing.T) {
+	app := newTestApp(config.Defaults{}, execServers...)
+
+	var mu sync.Mutex
+	outputs := make(map[string]*bytes.Buffer)
+	var done []string
+
+	results, err := app.Exec([]string{"web1", "broken", "web2"}, "uptime", ExecOptions{
+		Parallel: 2,
+		Output: func(server string) (io.Writer, io.Writer) {
+			mu.Lock()
+			defer mu.Unlock()
+			buf := &bytes.Buffer{}
+			outputs[server] = buf
+			return buf, buf
+		},
+		Done: func(r ExecResult) {
+			mu.Lock()
+			defer mu.Unlock()
+			done = append(done, r.Server)
+		},
+	})
+	if err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+
+	if len(results) != 3 || len(done) != 3 {
+		t.Fatalf("Exec() returned %d results and %d done calls, want 3", len(results), len(done))
+	}
+	for i, name := range []string{"web1", "broken", "web2"} {
+		if results[i].Server != name {
+			t.Errorf("results[%d].Server = %q, want %q", i, results[i].Server, name)
+		}
+	}
+	if results[0].Err != nil || results[0].ExitCode != 0 {
+		t.Errorf("web1 result = %+v, want success", results[0])
+	}
+	if results[1].Err == nil || results[1].ExitCode != 255 {
+		t.Errorf("broken result = %+v, want exit 255", results[1])
+	}
+	if got := strings.TrimSpace(outputs["web2"].String()); got != "ran uptime" {
+		t.Errorf("web2 output = %q, want %q", got, "ran uptime")
+	}
+}
+
+func TestExecUnknownServer(t *testing.T) {
+	app := newTestApp(config.Defaults{}, execServers...)
+
+	if _, err := app.Exec([]string{"web1", "missing"}, "uptime", ExecOptions{}); err == nil {
+		t.Error("Exec() should return error for an unknown server before running anything")
+	}
+}
@@ -0,0 +1,76 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/codoworks/sshto/internal/config"
+	"github.com/codoworks/sshto/internal/ssh"
+)
+
+// errJumpCycle is returned when a jump chain leads back to a server already on the path
+var errJumpCycle = errors.New("jump host cycle")
+
+// jumpChain resolves the jump hosts of an already resolved server, first hop first.
+// A jump inherited from defaults is ignored when it would lead back to the server
+// itself, so the bastions named in defaults can still be reached directly.
+func (a *App) jumpChain(s *config.Server, inherited bool, path []string) ([]config.Server, error) {
+	chain, err := a.resolveJumps(s.Jump, path)
+	if err != nil && inherited && errors.Is(err, errJumpCycle) {
+		return nil, nil
+	}
+	return chain, err
+}
+
+// resolveJumps expands a comma separated jump specification into its hops.
+// path holds the names of the servers currently being resolved.
+func (a *App) resolveJumps(spec string, path []string) ([]config.Server, error) {
+	if spec == "" || spec == config.JumpNone {
+		return nil, nil
+	}
+
+	var chain []config.Server
+	for _, ref := range config.SplitJump(spec) {
+		hops, err := a.resolveHop(ref, path)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, hops...)
+	}
+	return chain, nil
+}
+
+// resolveHop returns every hop needed to reach ref, ending with ref itself
+func (a *App) resolveHop(ref string, path []string) ([]config.Server, error) {
+	server, err := a.Config.FindServer(ref)
+	if err != nil {
+		// Only a reference that looks like a host is a raw [user@]host[:port];
+		// anything else is a server name, possibly misspelt or removed
+		if !config.IsJumpHost(ref) {
+			return nil, fmt.Errorf("jump host: %w", a.Config.ServerNotFound(ref))
+		}
+		hop, err := config.ParseJumpHost(ref)
+		if err != nil {
+			return nil, err
+		}
+		return []config.Server{*hop}, nil
+	}
+
+	if slices.Contains(path, server.Name) {
+		return nil, fmt.Errorf("%w: %s -> %s", errJumpCycle, strings.Join(path, " -> "), server.Name)
+	}
+
+	res := a.explainServer(server, ssh.ConnectOptions{})
+	hop := res.Server
+	chain, err := a.jumpChain(hop, res.Sources["jump"].inherited(), append(slices.Clone(path), server.Name))
+	if err != nil {
+		return nil, err
+	}
+
+	// Hops only relay the connection, so their own forwards don't apply
+	hop.JumpChain = nil
+	hop.Forwards = nil
+	return append(chain, *hop), nil
+}
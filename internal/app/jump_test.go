@@ -0,0 +1,154 @@
+package app
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/codoworks/sshto/internal/config"
+	"github.com/codoworks/sshto/internal/ssh"
+)
+
+func chainHosts(chain []config.Server) []string {
+	hosts := make([]string, len(chain))
+	for i, hop := range chain {
+		hosts[i] = hop.Host
+	}
+	return hosts
+}
+
+func TestResolveJumpChain(t *testing.T) {
+	app := newTestApp(config.Defaults{User: "deploy"},
+		config.Server{Name: "outer", Host: "outer.example.com", Port: 2222, Key: "~/.ssh/outer"},
+		config.Server{Name: "inner", Host: "inner.example.com", User: "ops", Jump: "outer"},
+		config.Server{Name: "db", Host: "10.0.0.5", Jump: "inner"},
+	)
+
+	resolved, err := app.Resolve("db", ssh.ConnectOptions{})
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+
+	hosts := chainHosts(resolved.JumpChain)
+	if len(hosts) != 2 || hosts[0] != "outer.example.com" || hosts[1] != "inner.example.com" {
+		t.Fatalf("JumpChain = %v, want [outer.example.com inner.example.com]", hosts)
+	}
+
+	// Each hop is resolved with its own settings plus defaults
+	outer := resolved.JumpChain[0]
+	if outer.User != "deploy" || outer.Port != 2222 || outer.Key != "~/.ssh/outer" {
+		t.Errorf("outer hop = %+v", outer)
+	}
+	if resolved.JumpChain[1].User != "ops" {
+		t.Errorf("inner hop user = %q, want %q", resolved.JumpChain[1].User, "ops")
+	}
+	if resolved.JumpChain[1].JumpChain != nil {
+		t.Error("hops should not carry their own JumpChain")
+	}
+}
+
+func TestResolveJumpRawAndList(t *testing.T) {
+	app := newTestApp(config.Defaults{},
+		config.Server{Name: "bastion", Host: "bastion.example.com"},
+		config.Server{Name: "web", Host: "10.0.0.1", Jump: "admin@gw.example.com:2200,bastion"},
+	)
+
+	resolved, err := app.Resolve("web", ssh.ConnectOptions{})
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+
+	if len(resolved.JumpChain) != 2 {
+		t.Fatalf("JumpChain length = %d, want 2", len(resolved.JumpChain))
+	}
+	raw := resolved.JumpChain[0]
+	if raw.User != "admin" || raw.Host != "gw.example.com" || raw.Port != 2200 {
+		t.Errorf("raw hop = %+v", raw)
+	}
+	if resolved.JumpChain[1].Host != "bastion.example.com" {
+		t.Errorf("second hop = %q, want %q", resolved.JumpChain[1].Host, "bastion.example.com")
+	}
+}
+
+func TestResolveJumpCycle(t *testing.T) {
+	app := newTestApp(config.Defaults{},
+		config.Server{Name: "a", Host: "a.example.com", Jump: "b"},
+		config.Server{Name: "b", Host: "b.example.com", Jump: "c"},
+		config.Server{Name: "c", Host: "c.example.com", Jump: "a"},
+	)
+
+	_, err := app.Resolve("a", ssh.ConnectOptions{})
+	if !errors.Is(err, errJumpCycle) {
+		t.Errorf("Resolve() error = %v, want jump cycle", err)
+	}
+
+	// A server jumping through itself is also a cycle
+	app = newTestApp(config.Defaults{}, config.Server{Name: "self", Host: "self.example.com", Jump: "self"})
+	if _, err := app.Resolve("self", ssh.ConnectOptions{}); !errors.Is(err, errJumpCycle) {
+		t.Errorf("Resolve(self) error = %v, want jump cycle", err)
+	}
+}
+
+func TestResolveDefaultJump(t *testing.T) {
+	app := newTestApp(config.Defaults{Jump: "bastion"},
+		config.Server{Name: "bastion", Host: "bastion.example.com"},
+		config.Server{Name: "web", Host: "10.0.0.1"},
+	)
+
+	resolved, err := app.Resolve("web", ssh.ConnectOptions{})
+	if err != nil {
+		t.Fatalf("Resolve(web) error = %v", err)
+	}
+	if len(resolved.JumpChain) != 1 {
+		t.Errorf("web JumpChain length = %d, want 1", len(resolved.JumpChain))
+	}
+
+	// The default bastion itself is reached directly
+	resolved, err = app.Resolve("bastion", ssh.ConnectOptions{})
+	if err != nil {
+		t.Fatalf("Resolve(bastion) error = %v", err)
+	}
+	if len(resolved.JumpChain) != 0 || resolved.Jump != "" {
+		t.Errorf("bastion Jump = %q, JumpChain = %v, want none", resolved.Jump, resolved.JumpChain)
+	}
+}
+
+func TestResolveJumpOverride(t *testing.T) {
+	app := newTestApp(config.Defaults{},
+		config.Server{Name: "bastion", Host: "bastion.example.com"},
+		config.Server{Name: "web", Host: "10.0.0.1", Jump: "bastion"},
+	)
+
+	resolved, err := app.Resolve("web", ssh.ConnectOptions{Jump: config.JumpNone})
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if len(resolved.JumpChain) != 0 {
+		t.Errorf("JumpChain = %v, want none with --jump none", resolved.JumpChain)
+	}
+
+	resolved, err = app.Resolve("web", ssh.ConnectOptions{Jump: "other.example.com"})
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if hosts := chainHosts(resolved.JumpChain); len(hosts) != 1 || hosts[0] != "other.example.com" {
+		t.Errorf("JumpChain = %v, want [other.example.com]", hosts)
+	}
+
+	if _, err := app.Resolve("web", ssh.ConnectOptions{Jump: "bad host"}); err == nil {
+		t.Error("Resolve() should return error for an invalid jump host")
+	}
+}
+
+func TestResolveJumpUnknownServer(t *testing.T) {
+	app := newTestApp(config.Defaults{},
+		config.Server{Name: "bastion", Host: "bastion.example.com"},
+		config.Server{Name: "web", Host: "10.0.0.1", Jump: "bastoin"},
+	)
+
+	// A name that does not look like a host is never dialed as one
+	_, err := app.Resolve("web", ssh.ConnectOptions{})
+	if err == nil || !strings.Contains(err.Error(), `did you mean "bastion"`) {
+		t.Errorf("Resolve() error = %v, want unknown server with a suggestion", err)
+	}
+}
@@ -0,0 +1,227 @@
+package app
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/codoworks/sshto/internal/config"
+	"github.com/codoworks/sshto/internal/ssh"
+)
+
+// Source names the configuration layer an effective value came from
+type Source string
+
+const (
+	SourceServer   Source = "server"
+	SourceDefaults Source = "defaults"
+	SourceFlag     Source = "flag"
+	SourceBuiltin  Source = "built-in"
+)
+
+// GroupSource returns the source for a value inherited from the named group
+func GroupSource(name string) Source {
+	return Source("group " + name)
+}
+
+// inherited reports whether the value came from a group or the defaults
+func (s Source) inherited() bool {
+	return s == SourceDefaults || strings.HasPrefix(string(s), "group ")
+}
+
+// Resolution is a resolved server together with the source of each value.
+// Sources is keyed by field name, with options keyed as "options.<Name>".
+type Resolution struct {
+	Server  *config.Server
+	Sources map[string]Source
+}
+
+// Field is a single effective setting as reported by Resolution.Fields
+type Field struct {
+	Name   string
+	Value  string
+	Source Source
+}
+
+// Fields lists the effective settings in display order. Unset fields have
+// an empty value and source.
+func (r *Resolution) Fields() []Field {
+	s := r.Server
+
+	port := ""
+	if s.Port != 0 {
+		port = strconv.Itoa(s.Port)
+	}
+	forwards := make([]string, len(s.Forwards))
+	for i, f := range s.Forwards {
+		forwards[i] = f.String()
+	}
+
+	fields := []Field{
+		{"host", s.Host, r.Sources["host"]},
+		{"user", s.User, r.Sources["user"]},
+		{"port", port, r.Sources["port"]},
+		{"key", s.Key, r.Sources["key"]},
+		{"jump", s.Jump, r.Sources["jump"]},
+		{"forwards", strings.Join(forwards, ", "), r.Sources["forwards"]},
+		{"transport", s.Transport, r.Sources["transport"]},
+	}
+	for _, key := range config.SortedOptionKeys(s.Options) {
+		fields = append(fields, Field{"options." + key, s.Options[key], r.Sources["options."+key]})
+	}
+	return fields
+}
+
+// layer is one level of connection settings
+type layer struct {
+	source    Source
+	user      string
+	port      int
+	key       string
+	jump      string
+	transport string
+	forwards  []config.Forward
+	options   map[string]string
+}
+
+// Explain resolves the named server like Resolve, also recording which
+// layer (flag, server, group, defaults) supplied each value
+func (a *App) Explain(serverName string, opts ssh.ConnectOptions) (*Resolution, error) {
+	server, err := a.Config.FindServer(serverName)
+	if err != nil {
+		return nil, err
+	}
+
+	res := a.explainServer(server, opts)
+
+	resolved := res.Server
+	resolved.JumpChain, err = a.jumpChain(resolved, res.Sources["jump"].inherited(), []string{server.Name})
+	if err != nil {
+		return nil, err
+	}
+	if len(resolved.JumpChain) == 0 {
+		resolved.Jump = ""
+		if res.Sources["jump"] != SourceFlag {
+			delete(res.Sources, "jump")
+		}
+	}
+
+	return res, nil
+}
+
+// explainServer applies command line overrides, the server's own settings,
+// its group and the defaults, in that order of precedence
+func (a *App) explainServer(s *config.Server, opts ssh.ConnectOptions) *Resolution {
+	flags := layer{
+		source:  SourceFlag,
+		user:    opts.User,
+		port:    opts.Port,
+		key:     opts.Key,
+		jump:    opts.Jump,
+		options: opts.Options,
+	}
+	layers := append([]layer{flags}, a.layers(s)...)
+
+	resolved := *s
+	resolved.User, resolved.Port, resolved.Key, resolved.Jump, resolved.Transport = "", 0, "", "", ""
+	resolved.Forwards, resolved.Options, resolved.JumpChain = nil, nil, nil
+	resolved.ExtraArgs, resolved.Command = opts.ExtraArgs, opts.Command
+
+	sources := map[string]Source{"host": SourceServer}
+	for _, l := range layers {
+		if resolved.User == "" && l.user != "" {
+			resolved.User, sources["user"] = l.user, l.source
+		}
+		if resolved.Port == 0 && l.port != 0 {
+			resolved.Port, sources["port"] = l.port, l.source
+		}
+		if resolved.Key == "" && l.key != "" {
+			resolved.Key, sources["key"] = l.key, l.source
+		}
+		if resolved.Jump == "" && l.jump != "" {
+			resolved.Jump, sources["jump"] = l.jump, l.source
+		}
+		if resolved.Transport == "" && l.transport != "" {
+			resolved.Transport, sources["transport"] = l.transport, l.source
+		}
+		if resolved.Forwards == nil && len(l.forwards) > 0 && !opts.NoForwards {
+			resolved.Forwards, sources["forwards"] = slices.Clone(l.forwards), l.source
+		}
+	}
+
+	if resolved.Port == 0 {
+		resolved.Port, sources["port"] = 22, SourceBuiltin
+	}
+
+	// Forwards given on the command line add to the configured ones
+	if len(opts.Forwards) > 0 {
+		if resolved.Forwards == nil {
+			sources["forwards"] = SourceFlag
+		} else {
+			sources["forwards"] += ", " + SourceFlag
+		}
+		resolved.Forwards = append(resolved.Forwards, opts.Forwards...)
+	}
+
+	// Options merge by name, least specific layer first
+	for i := len(layers) - 1; i >= 0; i-- {
+		for _, key := range config.SortedOptionKeys(layers[i].options) {
+			for existing := range resolved.Options {
+				if strings.EqualFold(existing, key) {
+					delete(resolved.Options, existing)
+					delete(sources, "options."+existing)
+				}
+			}
+			if resolved.Options == nil {
+				resolved.Options = make(map[string]string)
+			}
+			resolved.Options[key] = layers[i].options[key]
+			sources["options."+key] = layers[i].source
+		}
+	}
+
+	return &Resolution{Server: &resolved, Sources: sources}
+}
+
+// layers returns the configured settings that apply to s, most specific first
+func (a *App) layers(s *config.Server) []layer {
+	layers := []layer{{
+		source:    SourceServer,
+		user:      s.User,
+		port:      s.Port,
+		key:       s.Key,
+		jump:      s.Jump,
+		transport: s.Transport,
+		forwards:  s.Forwards,
+		options:   s.Options,
+	}}
+
+	for _, g := range a.groupChain(s.Group) {
+		layers = append(layers, layer{
+			source:   GroupSource(g.Name),
+			user:     g.User,
+			port:     g.Port,
+			key:      g.Key,
+			jump:     g.Jump,
+			forwards: g.Forwards,
+			options:  g.Options,
+		})
+	}
+
+	d := a.Config.Defaults
+	return append(layers, layer{
+		source:    SourceDefaults,
+		user:      d.User,
+		port:      d.Port,
+		key:       d.Key,
+		jump:      d.Jump,
+		transport: d.Transport,
+		options:   d.Options,
+	})
+}
+
+// groupChain returns the groups whose settings a server in the named group
+// inherits: the group itself, then its parents up to the top of the tree
+func (a *App) groupChain(name string) []config.Group {
+	return a.Config.GroupAncestors(name)
+}
@@ -0,0 +1,231 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/codoworks/sshto/internal/config"
+	"github.com/codoworks/sshto/internal/ssh"
+)
+
+// newResolveTestApp returns an app whose servers inherit from a production
+// group and from defaults
+func newResolveTestApp() *App {
+	app := newTestApp(
+		config.Defaults{
+			User:    "nobody",
+			Port:    2200,
+			Key:     "~/.ssh/default",
+			Options: map[string]string{"ServerAliveInterval": "30", "forwardagent": "yes"},
+		},
+		config.Server{Name: "bastion", Host: "bastion.example.com", Group: "production"},
+		config.Server{Name: "web", Host: "10.0.0.1", Group: "production", Port: 2222},
+		config.Server{Name: "db", Host: "10.0.0.2", Group: "production", User: "postgres", Jump: "none"},
+		config.Server{Name: "loose", Host: "10.0.0.3", Group: "undefined"},
+	)
+	app.Config.Groups = []config.Group{
+		{
+			Name:     "production",
+			User:     "deploy",
+			Key:      "~/.ssh/prod",
+			Jump:     "bastion",
+			Forwards: []config.Forward{{Type: config.ForwardDynamic, Listen: "1080"}},
+			Options:  map[string]string{"ForwardAgent": "no"},
+		},
+	}
+	return app
+}
+
+func TestExplainGroupInheritance(t *testing.T) {
+	app := newResolveTestApp()
+
+	res, err := app.Explain("web", ssh.ConnectOptions{})
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+
+	s := res.Server
+	if s.User != "deploy" || s.Key != "~/.ssh/prod" || s.Port != 2222 || s.Jump != "bastion" {
+		t.Errorf("resolved = %+v", s)
+	}
+	if len(s.JumpChain) != 1 || s.JumpChain[0].Host != "bastion.example.com" {
+		t.Errorf("JumpChain = %+v", s.JumpChain)
+	}
+
+	expected := map[string]Source{
+		"host":                        SourceServer,
+		"user":                        GroupSource("production"),
+		"port":                        SourceServer,
+		"key":                         GroupSource("production"),
+		"jump":                        GroupSource("production"),
+		"forwards":                    GroupSource("production"),
+		"options.ForwardAgent":        GroupSource("production"),
+		"options.ServerAliveInterval": SourceDefaults,
+	}
+	for field, source := range expected {
+		if res.Sources[field] != source {
+			t.Errorf("Sources[%s] = %q, want %q", field, res.Sources[field], source)
+		}
+	}
+	if _, ok := res.Sources["options.forwardagent"]; ok {
+		t.Error("overridden option should not keep a source")
+	}
+}
+
+func TestExplainGroupJumpSkipsBastion(t *testing.T) {
+	app := newResolveTestApp()
+
+	// The bastion inherits its own name as jump from the group; that must not cycle
+	res, err := app.Explain("bastion", ssh.ConnectOptions{})
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+	if res.Server.Jump != "" || len(res.Server.JumpChain) != 0 {
+		t.Errorf("bastion Jump = %q, JumpChain = %v, want none", res.Server.Jump, res.Server.JumpChain)
+	}
+	if _, ok := res.Sources["jump"]; ok {
+		t.Errorf("Sources[jump] = %q, want unset", res.Sources["jump"])
+	}
+}
+
+func TestExplainServerOverridesGroup(t *testing.T) {
+	app := newResolveTestApp()
+
+	res, err := app.Explain("db", ssh.ConnectOptions{})
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+	if res.Server.User != "postgres" || res.Sources["user"] != SourceServer {
+		t.Errorf("user = %q from %q", res.Server.User, res.Sources["user"])
+	}
+	if len(res.Server.JumpChain) != 0 {
+		t.Errorf("JumpChain = %v, want none for jump: none", res.Server.JumpChain)
+	}
+	if res.Server.Port != 2200 || res.Sources["port"] != SourceDefaults {
+		t.Errorf("port = %d from %q", res.Server.Port, res.Sources["port"])
+	}
+}
+
+func TestExplainFlags(t *testing.T) {
+	app := newResolveTestApp()
+
+	opts := ssh.ConnectOptions{
+		User:     "root",
+		Jump:     config.JumpNone,
+		Forwards: []config.Forward{{Type: config.ForwardLocal, Listen: "8080", Target: "localhost:80"}},
+		Options:  map[string]string{"forwardAgent": "yes"},
+
+		ExtraArgs: []string{"-A"},
+		Command:   "uptime",
+	}
+	res, err := app.Explain("web", opts)
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+
+	if res.Server.User != "root" || res.Sources["user"] != SourceFlag {
+		t.Errorf("user = %q from %q", res.Server.User, res.Sources["user"])
+	}
+	if res.Sources["jump"] != SourceFlag || len(res.Server.JumpChain) != 0 {
+		t.Errorf("jump source = %q, chain = %v", res.Sources["jump"], res.Server.JumpChain)
+	}
+	if len(res.Server.Forwards) != 2 || res.Sources["forwards"] != GroupSource("production")+", "+SourceFlag {
+		t.Errorf("forwards = %v from %q", res.Server.Forwards, res.Sources["forwards"])
+	}
+	if res.Server.Options["forwardAgent"] != "yes" || res.Sources["options.forwardAgent"] != SourceFlag {
+		t.Errorf("options = %v", res.Server.Options)
+	}
+	if len(res.Server.ExtraArgs) != 1 || res.Server.Command != "uptime" {
+		t.Errorf("extra args = %v, command = %q", res.Server.ExtraArgs, res.Server.Command)
+	}
+}
+
+func TestExplainUndefinedGroup(t *testing.T) {
+	app := newResolveTestApp()
+
+	res, err := app.Explain("loose", ssh.ConnectOptions{})
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+	if res.Server.User != "nobody" || res.Sources["user"] != SourceDefaults {
+		t.Errorf("user = %q from %q", res.Server.User, res.Sources["user"])
+	}
+}
+
+func TestExplainNestedGroups(t *testing.T) {
+	app := &App{
+		Config: &config.Config{
+			Groups: []config.Group{
+				{Name: "prod", User: "deploy", Port: 2200, Options: map[string]string{"ForwardAgent": "no"}},
+				{Name: "eu", Parent: "prod", Port: 2222},
+				{Name: "web", Parent: "eu", Options: map[string]string{"Compression": "yes"}},
+				{Name: "loop", Parent: "loop", User: "looped"},
+			},
+			Servers: []config.Server{
+				{Name: "web1", Host: "10.0.0.1", Group: "web"},
+				{Name: "spin", Host: "10.0.0.2", Group: "loop"},
+			},
+		},
+		SSHClient: ssh.NewClient(),
+	}
+
+	res, err := app.Explain("web1", ssh.ConnectOptions{})
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+	if res.Server.User != "deploy" || res.Sources["user"] != GroupSource("prod") {
+		t.Errorf("user = %q from %q, want deploy from group prod", res.Server.User, res.Sources["user"])
+	}
+	if res.Server.Port != 2222 || res.Sources["port"] != GroupSource("eu") {
+		t.Errorf("port = %d from %q, want 2222 from group eu", res.Server.Port, res.Sources["port"])
+	}
+	if res.Server.Options["ForwardAgent"] != "no" || res.Server.Options["Compression"] != "yes" {
+		t.Errorf("options = %v, want options from both web and prod", res.Server.Options)
+	}
+
+	// A group that is its own parent is only applied once
+	res, err = app.Explain("spin", ssh.ConnectOptions{})
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+	if res.Server.User != "looped" {
+		t.Errorf("user = %q, want %q", res.Server.User, "looped")
+	}
+}
+
+func TestExplainBuiltinPort(t *testing.T) {
+	app := &App{
+		Config:    &config.Config{Servers: []config.Server{{Name: "web", Host: "10.0.0.1"}}},
+		SSHClient: ssh.NewClient(),
+	}
+
+	res, err := app.Explain("web", ssh.ConnectOptions{})
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+	if res.Server.Port != 22 || res.Sources["port"] != SourceBuiltin {
+		t.Errorf("port = %d from %q", res.Server.Port, res.Sources["port"])
+	}
+}
+
+func TestResolutionFields(t *testing.T) {
+	app := newResolveTestApp()
+
+	res, err := app.Explain("web", ssh.ConnectOptions{})
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+
+	fields := res.Fields()
+	names := []string{"host", "user", "port", "key", "jump", "forwards", "transport", "options.ForwardAgent", "options.ServerAliveInterval"}
+	if len(fields) != len(names) {
+		t.Fatalf("Fields() returned %d fields, want %d", len(fields), len(names))
+	}
+	for i, name := range names {
+		if fields[i].Name != name {
+			t.Errorf("Fields()[%d].Name = %q, want %q", i, fields[i].Name, name)
+		}
+	}
+	if fields[2].Value != "2222" || fields[5].Value != "D:1080" {
+		t.Errorf("Fields() = %+v", fields)
+	}
+}
@@ -0,0 +1,95 @@
+package app
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/codoworks/sshto/internal/config"
+	"github.com/codoworks/sshto/internal/probe"
+	"github.com/codoworks/sshto/internal/ssh"
+)
+
+// StatusOptions controls how Status checks servers
+type StatusOptions struct {
+	Connect  ssh.ConnectOptions
+	Parallel int           // maximum concurrent checks, 1 if unset
+	Timeout  time.Duration // per check, for the TCP dial and the ssh connect; 5s if unset
+	TCPOnly  bool          // skip the ssh login check
+}
+
+// Status checks whether each named server is reachable. A TCP dial to the
+// server, or to its first jump host, comes first; servers that answer are
+// then logged into with the ssh client unless TCPOnly is set. A server that
+// cannot be resolved, e.g. for a missing jump host, is reported as an error
+// without stopping the checks of the others.
+func (a *App) Status(names []string, opts StatusOptions) ([]probe.Result, error) {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Second
+	}
+	for _, name := range names {
+		if _, err := a.Config.FindServer(name); err != nil {
+			return nil, err
+		}
+	}
+
+	results := make([]probe.Result, len(names))
+	parallelize(len(names), opts.Parallel, func(i int) {
+		s, err := a.Resolve(names[i], opts.Connect)
+		if err != nil {
+			results[i] = probe.Result{Server: names[i], State: probe.StateError, Detail: err.Error()}
+			return
+		}
+		results[i] = a.checkOne(context.Background(), s, opts)
+	})
+
+	return results, nil
+}
+
+// Ping dials the named server's ssh port, or its first jump host's, without
+// logging in. Cancelling ctx abandons the dial.
+func (a *App) Ping(ctx context.Context, name string, timeout time.Duration) probe.Result {
+	s, err := a.Resolve(name, ssh.ConnectOptions{})
+	if err != nil {
+		return probe.Result{Server: name, State: probe.StateError, Detail: err.Error()}
+	}
+	return a.checkOne(ctx, s, StatusOptions{Timeout: timeout, TCPOnly: true})
+}
+
+// checkOne checks a single resolved server
+func (a *App) checkOne(ctx context.Context, s *config.Server, opts StatusOptions) probe.Result {
+	// Servers behind a jump host are usually not reachable directly
+	target := s
+	if len(s.JumpChain) > 0 {
+		target = &s.JumpChain[0]
+	}
+
+	res := probe.Dial(ctx, DialAddress(target), opts.Timeout)
+	res.Server = s.Name
+	if len(s.JumpChain) > 0 && res.OK() {
+		res.Detail = "via " + target.Name
+	}
+	if !res.OK() || opts.TCPOnly {
+		return res
+	}
+
+	checked := *s
+	if seconds := int(opts.Timeout.Round(time.Second).Seconds()); seconds > 0 {
+		// Options set by the user still win
+		checked.Options = config.MergeOptions(map[string]string{"ConnectTimeout": strconv.Itoa(seconds)}, s.Options)
+	}
+	if err := a.SSHClient.TestConnection(&checked); err != nil {
+		res.State, res.Detail = probe.ClassifySSH(err.Error())
+	}
+	return res
+}
+
+// DialAddress returns the host:port a server's ssh daemon listens on
+func DialAddress(s *config.Server) string {
+	port := s.Port
+	if port == 0 {
+		port = 22
+	}
+	return net.JoinHostPort(s.Host, strconv.Itoa(port))
+}
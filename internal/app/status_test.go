@@ -0,0 +1,132 @@
+package app
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codoworks/sshto/internal/config"
+	"github.com/codoworks/sshto/internal/probe"
+)
+
+// newStatusTestApp returns an app whose servers point at a local listener
+// (open) and at a port nothing listens on (closed)
+func newStatusTestApp(t *testing.T) *App {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	open := ln.Addr().(*net.TCPAddr).Port
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	closedPort := closed.Addr().(*net.TCPAddr).Port
+	closed.Close()
+
+	return newTestApp(config.Defaults{},
+		config.Server{Name: "up", Host: "127.0.0.1", Port: open},
+		config.Server{Name: "down", Host: "127.0.0.1", Port: closedPort},
+		config.Server{Name: "denied", Host: "127.0.0.1", Port: open, User: "bad"},
+		config.Server{Name: "bastion", Host: "127.0.0.1", Port: open},
+		config.Server{Name: "private", Host: "10.255.255.1", Jump: "bastion"},
+	)
+}
+
+func TestStatusTCPOnly(t *testing.T) {
+	app := newStatusTestApp(t)
+
+	results, err := app.Status([]string{"up", "down", "private"}, StatusOptions{TCPOnly: true, Timeout: time.Second, Parallel: 3})
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+
+	want := []probe.State{probe.StateReachable, probe.StateRefused, probe.StateReachable}
+	for i, state := range want {
+		if results[i].State != state {
+			t.Errorf("results[%d] = %+v, want %q", i, results[i], state)
+		}
+	}
+
+	// Servers behind a jump host are checked through their first hop
+	bastion, _ := app.Config.FindServer("bastion")
+	if results[2].Address != DialAddress(bastion) || results[2].Detail != "via bastion" {
+		t.Errorf("private result = %+v, want dial to bastion", results[2])
+	}
+}
+
+func TestStatusSSH(t *testing.T) {
+	app := newStatusTestApp(t)
+
+	results, err := app.Status([]string{"up", "denied"}, StatusOptions{Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if !results[0].OK() {
+		t.Errorf("up result = %+v, want reachable", results[0])
+	}
+	if results[1].State != probe.StateAuthFailed {
+		t.Errorf("denied result = %+v, want auth-failed", results[1])
+	}
+}
+
+func TestPing(t *testing.T) {
+	app := newStatusTestApp(t)
+
+	if res := app.Ping(context.Background(), "up", time.Second); !res.OK() || res.Server != "up" {
+		t.Errorf("Ping(up) = %+v, want reachable", res)
+	}
+	if res := app.Ping(context.Background(), "down", time.Second); res.State != probe.StateRefused {
+		t.Errorf("Ping(down) = %+v, want refused", res)
+	}
+	if res := app.Ping(context.Background(), "missing", time.Second); res.State != probe.StateError {
+		t.Errorf("Ping(missing) = %+v, want error", res)
+	}
+}
+
+func TestStatusBrokenServer(t *testing.T) {
+	app := newStatusTestApp(t)
+	app.Config.Servers = append(app.Config.Servers, config.Server{Name: "orphan", Host: "10.0.0.1", Jump: "gone"})
+
+	results, err := app.Status([]string{"orphan", "up"}, StatusOptions{TCPOnly: true, Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if results[0].State != probe.StateError || !strings.Contains(results[0].Detail, `"gone" not found`) {
+		t.Errorf("orphan result = %+v, want an error for its jump host", results[0])
+	}
+	if !results[1].OK() {
+		t.Errorf("up result = %+v, want reachable", results[1])
+	}
+}
+
+func TestStatusUnknownServer(t *testing.T) {
+	app := newStatusTestApp(t)
+
+	if _, err := app.Status([]string{"missing"}, StatusOptions{}); err == nil {
+		t.Error("Status() should return error for an unknown server")
+	}
+}
+
+func TestDialAddress(t *testing.T) {
+	tests := []struct {
+		server config.Server
+		want   string
+	}{
+		{config.Server{Host: "example.com"}, "example.com:22"},
+		{config.Server{Host: "example.com", Port: 2222}, "example.com:2222"},
+		{config.Server{Host: "::1", Port: 22}, "[::1]:22"},
+	}
+
+	for _, tt := range tests {
+		if got := DialAddress(&tt.server); got != tt.want {
+			t.Errorf("DialAddress(%q:%s) = %q, want %q", tt.server.Host, strconv.Itoa(tt.server.Port), got, tt.want)
+		}
+	}
+}
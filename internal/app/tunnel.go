@@ -0,0 +1,144 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/codoworks/sshto/internal/config"
+	"github.com/codoworks/sshto/internal/ssh"
+	"github.com/codoworks/sshto/internal/tunnel"
+)
+
+// tunnelGrace is how long a tunnel with nothing to check locally is watched
+// for an early exit before it is considered started
+const tunnelGrace = 2 * time.Second
+
+// StartTunnel starts a background forward-only connection to the named server
+// for its configured forwards plus any given in opts. It waits up to timeout
+// for the local ports to accept connections and fails if ssh exits first.
+func (a *App) StartTunnel(name string, opts ssh.ConnectOptions, timeout time.Duration) (*tunnel.Tunnel, error) {
+	resolved, err := a.resolveTunnel(name, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := a.Tunnels.Get(resolved.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.Alive() {
+		return nil, fmt.Errorf("tunnel to %s is already running (pid %d)", resolved.Name, existing.PID)
+	}
+
+	if err := os.MkdirAll(a.Tunnels.Dir(), 0755); err != nil {
+		return nil, fmt.Errorf("creating tunnel directory: %w", err)
+	}
+	logPath := a.Tunnels.LogPath(resolved.Name)
+	log, err := os.Create(logPath)
+	if err != nil {
+		return nil, fmt.Errorf("creating tunnel log: %w", err)
+	}
+	proc, err := a.SSHClient.StartTunnel(resolved, log)
+	log.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	// Reap ssh if it exits while sshto is still running
+	exited := make(chan error, 1)
+	go func() { exited <- proc.Wait() }()
+
+	t := tunnel.Tunnel{
+		Server:       resolved.Name,
+		PID:          proc.PID,
+		ProcessStart: tunnel.ProcessStart(proc.PID),
+		Forwards:     resolved.Forwards,
+		Started:      time.Now(),
+		Log:          logPath,
+	}
+	if err := a.Tunnels.Save(t); err != nil {
+		_ = t.Stop()
+		return nil, err
+	}
+
+	if err := waitForTunnel(t, exited, timeout); err != nil {
+		_ = a.Tunnels.Remove(t.Server)
+		return nil, err
+	}
+	return &t, nil
+}
+
+// waitForTunnel waits until the tunnel's local ports accept connections,
+// which ssh only opens once it has logged in. A tunnel that is still running
+// when timeout expires is left running, as the login may just be slow.
+// Tunnels with only remote forwards have nothing to check locally, so they
+// are watched for a short grace period instead.
+func waitForTunnel(t tunnel.Tunnel, exited <-chan error, timeout time.Duration) error {
+	local := len(t.LocalAddresses()) > 0
+	if !local {
+		timeout = min(timeout, tunnelGrace)
+	}
+
+	deadline := time.After(timeout)
+	tick := time.NewTicker(100 * time.Millisecond)
+	defer tick.Stop()
+
+	for {
+		select {
+		case err := <-exited:
+			return fmt.Errorf("tunnel to %s exited: %s", t.Server, exitReason(t.Log, err))
+		case <-deadline:
+			return nil
+		case <-tick.C:
+			if local && t.Check(time.Second) == tunnel.HealthUp {
+				return nil
+			}
+		}
+	}
+}
+
+// exitReason returns the last line ssh logged, or err if it logged nothing
+func exitReason(logPath string, err error) string {
+	data, _ := os.ReadFile(logPath)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if last := strings.TrimSpace(lines[len(lines)-1]); last != "" {
+		return last
+	}
+	if err == nil {
+		return "ssh exited"
+	}
+	return err.Error()
+}
+
+// resolveTunnel resolves the named server for a tunnel, which needs forwards
+func (a *App) resolveTunnel(name string, opts ssh.ConnectOptions) (*config.Server, error) {
+	resolved, err := a.Resolve(name, opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(resolved.Forwards) == 0 {
+		return nil, fmt.Errorf("server %q has no forwards to tunnel (add some or use --forward)", resolved.Name)
+	}
+	return resolved, nil
+}
+
+// StopTunnel stops the named server's tunnel and forgets it
+func (a *App) StopTunnel(name string) (*tunnel.Tunnel, error) {
+	t, err := a.Tunnels.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, fmt.Errorf("no tunnel running for %q", name)
+	}
+
+	if err := t.Stop(); err != nil {
+		return nil, err
+	}
+	if err := a.Tunnels.Remove(t.Server); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
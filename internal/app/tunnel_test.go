@@ -0,0 +1,86 @@
+package app
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codoworks/sshto/internal/config"
+	"github.com/codoworks/sshto/internal/ssh"
+	"github.com/codoworks/sshto/internal/tunnel"
+)
+
+func newTunnelTestApp(t *testing.T) *App {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "sshto-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	// Remote forwards leave nothing to check locally, so fakeSSH can pass
+	forward := config.Forward{Type: config.ForwardRemote, Listen: "8080", Target: "localhost:80"}
+	app := newTestApp(config.Defaults{},
+		config.Server{Name: "db", Host: "10.0.0.1", Forwards: []config.Forward{forward}},
+		config.Server{Name: "broken", Host: "bad.example.com", Forwards: []config.Forward{forward}},
+		config.Server{Name: "plain", Host: "10.0.0.2"},
+	)
+	app.Tunnels = tunnel.NewStore(dir)
+	return app
+}
+
+func TestStartStopTunnel(t *testing.T) {
+	app := newTunnelTestApp(t)
+
+	tun, err := app.StartTunnel("db", ssh.ConnectOptions{}, 200*time.Millisecond)
+	if err != nil {
+		t.Fatalf("StartTunnel() error = %v", err)
+	}
+	if !tun.Alive() {
+		t.Fatalf("tunnel process %d is not running", tun.PID)
+	}
+
+	if _, err := app.StartTunnel("db", ssh.ConnectOptions{}, 200*time.Millisecond); err == nil || !strings.Contains(err.Error(), "already running") {
+		t.Errorf("second StartTunnel() error = %v, want already running", err)
+	}
+
+	tunnels, _ := app.Tunnels.List()
+	if len(tunnels) != 1 || tunnels[0].PID != tun.PID {
+		t.Errorf("List() = %+v, want the started tunnel", tunnels)
+	}
+
+	if _, err := app.StopTunnel("db"); err != nil {
+		t.Fatalf("StopTunnel() error = %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for tun.Alive() && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if tun.Alive() {
+		t.Errorf("tunnel process %d still running after StopTunnel()", tun.PID)
+	}
+	if got, _ := app.Tunnels.Get("db"); got != nil {
+		t.Errorf("Get() after StopTunnel() = %+v, want nil", got)
+	}
+
+	if _, err := app.StopTunnel("db"); err == nil {
+		t.Error("StopTunnel() of stopped tunnel expected error, got nil")
+	}
+}
+
+func TestStartTunnelErrors(t *testing.T) {
+	app := newTunnelTestApp(t)
+
+	_, err := app.StartTunnel("broken", ssh.ConnectOptions{}, time.Second)
+	if err == nil || !strings.Contains(err.Error(), "Permission denied") {
+		t.Errorf("StartTunnel(broken) error = %v, want ssh's message", err)
+	}
+	if got, _ := app.Tunnels.Get("broken"); got != nil {
+		t.Errorf("failed tunnel was recorded: %+v", got)
+	}
+
+	if _, err := app.StartTunnel("plain", ssh.ConnectOptions{}, time.Second); err == nil || !strings.Contains(err.Error(), "no forwards") {
+		t.Errorf("StartTunnel(plain) error = %v, want no forwards", err)
+	}
+}
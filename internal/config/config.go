@@ -14,6 +14,11 @@ type Defaults struct {
 	User string `yaml:"user,omitempty"`
 	Port int    `yaml:"port,omitempty"`
 	Key  string `yaml:"key,omitempty"`
+	Jump string `yaml:"jump,omitempty"`
+
+	Transport string `yaml:"transport,omitempty"`
+
+	Options map[string]string `yaml:"options,omitempty"`
 }
 
 // Config represents the full configuration file
@@ -22,6 +27,11 @@ type Config struct {
 	Servers  []Server `yaml:"servers"`
 	Defaults Defaults `yaml:"defaults,omitempty"`
 
+	// SSHBinary and SCPBinary replace the ssh and scp programs found on PATH,
+	// e.g. with a wrapper installed elsewhere
+	SSHBinary string `yaml:"ssh_binary,omitempty"`
+	SCPBinary string `yaml:"scp_binary,omitempty"`
+
 	path string // internal: path to config file
 }
 
@@ -142,15 +152,26 @@ func (c *Config) AddGroup(g Group) error {
 			return fmt.Errorf("group %q already exists", g.Name)
 		}
 	}
+	if g.Parent != "" {
+		if _, err := c.FindGroup(g.Parent); err != nil {
+			return fmt.Errorf("parent %w", err)
+		}
+	}
 	c.Groups = append(c.Groups, g)
 	return nil
 }
 
-// RemoveGroup removes a group by name
+// RemoveGroup removes a group by name. Its child groups move up to its parent.
 func (c *Config) RemoveGroup(name string) error {
 	for i := range c.Groups {
 		if c.Groups[i].Name == name {
+			parent := c.Groups[i].Parent
 			c.Groups = append(c.Groups[:i], c.Groups[i+1:]...)
+			for j := range c.Groups {
+				if c.Groups[j].Parent == name {
+					c.Groups[j].Parent = parent
+				}
+			}
 			return nil
 		}
 	}
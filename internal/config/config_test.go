@@ -247,6 +247,14 @@ func TestAddGroup(t *testing.T) {
 	if err == nil {
 		t.Error("AddGroup() should return error for duplicate name")
 	}
+
+	// Nested group
+	if err := cfg.AddGroup(Group{Name: "eu", Parent: "production"}); err != nil {
+		t.Errorf("AddGroup() with existing parent error = %v", err)
+	}
+	if err := cfg.AddGroup(Group{Name: "us", Parent: "missing"}); err == nil {
+		t.Error("AddGroup() should return error for missing parent")
+	}
 }
 
 func TestRemoveGroup(t *testing.T) {
@@ -273,6 +281,24 @@ func TestRemoveGroup(t *testing.T) {
 	}
 }
 
+func TestRemoveGroupReparentsChildren(t *testing.T) {
+	cfg := &Config{
+		Groups: []Group{
+			{Name: "prod"},
+			{Name: "eu", Parent: "prod"},
+			{Name: "web", Parent: "eu"},
+		},
+	}
+
+	if err := cfg.RemoveGroup("eu"); err != nil {
+		t.Fatalf("RemoveGroup() error = %v", err)
+	}
+	web, _ := cfg.FindGroup("web")
+	if web.Parent != "prod" {
+		t.Errorf("web.Parent = %q, want %q", web.Parent, "prod")
+	}
+}
+
 func TestServersByGroup(t *testing.T) {
 	cfg := &Config{
 		Servers: []Server{
@@ -335,3 +361,25 @@ func TestSaveCreatesDirectory(t *testing.T) {
 		t.Errorf("Config file was not created: %v", err)
 	}
 }
+
+// loadTestConfig writes content to a temporary config file and loads it
+func loadTestConfig(t *testing.T, content string) *Config {
+	t.Helper()
+
+	tmpDir, err := os.MkdirTemp("", "sshto-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(tmpDir) })
+
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	return cfg
+}
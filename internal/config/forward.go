@@ -0,0 +1,190 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// ForwardType is the kind of port forward
+type ForwardType string
+
+const (
+	ForwardLocal   ForwardType = "local"
+	ForwardRemote  ForwardType = "remote"
+	ForwardDynamic ForwardType = "dynamic"
+)
+
+// Forward represents a port forward opened alongside a connection
+type Forward struct {
+	Type   ForwardType `yaml:"type"`
+	Listen string      `yaml:"listen"`           // [bind_address:]port
+	Target string      `yaml:"target,omitempty"` // host:hostport, unused for dynamic forwards
+}
+
+// forwardPrefixes maps the accepted spec prefixes to forward types
+var forwardPrefixes = map[string]ForwardType{
+	"l":       ForwardLocal,
+	"local":   ForwardLocal,
+	"r":       ForwardRemote,
+	"remote":  ForwardRemote,
+	"d":       ForwardDynamic,
+	"dynamic": ForwardDynamic,
+}
+
+// ParseForward parses a forward spec such as "L:5432:localhost:5432",
+// "remote:8080:localhost:80" or "D:1080". The part after the type uses
+// the same syntax as ssh's -L, -R and -D flags.
+func ParseForward(spec string) (Forward, error) {
+	prefix, rest, ok := strings.Cut(strings.TrimSpace(spec), ":")
+	typ, known := forwardPrefixes[strings.ToLower(prefix)]
+	if !ok || !known {
+		return Forward{}, fmt.Errorf("invalid forward %q: must start with L:, R: or D:", spec)
+	}
+
+	fields := splitForward(rest)
+	f := Forward{Type: typ}
+	switch {
+	case typ == ForwardDynamic && (len(fields) == 1 || len(fields) == 2):
+		f.Listen = rest
+	case typ != ForwardDynamic && (len(fields) == 3 || len(fields) == 4):
+		n := len(fields)
+		f.Listen = strings.Join(fields[:n-2], ":")
+		f.Target = fields[n-2] + ":" + fields[n-1]
+	default:
+		return Forward{}, fmt.Errorf("invalid forward %q", spec)
+	}
+
+	if err := f.Validate(); err != nil {
+		return Forward{}, err
+	}
+	return f, nil
+}
+
+// ParseForwards parses a comma separated list of forward specs
+func ParseForwards(specs string) ([]Forward, error) {
+	var forwards []Forward
+	for _, spec := range strings.Split(specs, ",") {
+		if strings.TrimSpace(spec) == "" {
+			continue
+		}
+		f, err := ParseForward(spec)
+		if err != nil {
+			return nil, err
+		}
+		forwards = append(forwards, f)
+	}
+	return forwards, nil
+}
+
+// Validate checks that the forward is well formed
+func (f Forward) Validate() error {
+	switch f.Type {
+	case ForwardLocal, ForwardRemote, ForwardDynamic:
+	default:
+		return fmt.Errorf("invalid forward type %q (use local, remote or dynamic)", f.Type)
+	}
+
+	listen := splitForward(f.Listen)
+	if len(listen) == 0 || len(listen) > 2 {
+		return fmt.Errorf("invalid forward listen address %q", f.Listen)
+	}
+	if err := validateForwardPort(listen[len(listen)-1]); err != nil {
+		return fmt.Errorf("invalid forward listen address %q: %w", f.Listen, err)
+	}
+
+	if f.Type == ForwardDynamic {
+		if f.Target != "" {
+			return fmt.Errorf("dynamic forward %q cannot have a target", f.Listen)
+		}
+		return nil
+	}
+
+	target := splitForward(f.Target)
+	if len(target) != 2 || target[0] == "" {
+		return fmt.Errorf("invalid forward target %q (use host:port)", f.Target)
+	}
+	if err := validateForwardPort(target[1]); err != nil {
+		return fmt.Errorf("invalid forward target %q: %w", f.Target, err)
+	}
+	return nil
+}
+
+// Spec returns the argument ssh expects after -L, -R or -D
+func (f Forward) Spec() string {
+	if f.Type == ForwardDynamic {
+		return f.Listen
+	}
+	return f.Listen + ":" + f.Target
+}
+
+// String returns the forward in the form accepted by ParseForward
+func (f Forward) String() string {
+	return strings.ToUpper(string(f.Type[:1])) + ":" + f.Spec()
+}
+
+// ListenPort returns the port the forward listens on
+func (f Forward) ListenPort() int {
+	fields := splitForward(f.Listen)
+	if len(fields) == 0 {
+		return 0
+	}
+	port, _ := strconv.Atoi(fields[len(fields)-1])
+	return port
+}
+
+// ListenAddress returns the local host:port a local or dynamic forward
+// accepts connections on. ssh binds to the loopback address unless a bind
+// address is given; "*" and an empty bind address mean all interfaces.
+func (f Forward) ListenAddress() string {
+	fields := splitForward(f.Listen)
+	if len(fields) == 0 {
+		return ""
+	}
+	host := "localhost"
+	if len(fields) == 2 {
+		host = strings.Trim(fields[0], "[]")
+		if host == "" || host == "*" {
+			host = "localhost"
+		}
+	}
+	return net.JoinHostPort(host, fields[len(fields)-1])
+}
+
+// splitForward splits a colon separated forward spec, keeping
+// bracketed IPv6 addresses together
+func splitForward(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	var fields []string
+	var b strings.Builder
+	depth := 0
+	for _, r := range s {
+		switch {
+		case r == '[':
+			depth++
+		case r == ']':
+			depth--
+		case r == ':' && depth == 0:
+			fields = append(fields, b.String())
+			b.Reset()
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return append(fields, b.String())
+}
+
+func validateForwardPort(s string) error {
+	port, err := strconv.Atoi(s)
+	if err != nil {
+		return fmt.Errorf("port must be a number")
+	}
+	if port < 1 || port > 65535 {
+		return fmt.Errorf("port must be between 1 and 65535")
+	}
+	return nil
+}
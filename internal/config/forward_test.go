@@ -0,0 +1,133 @@
+package config
+
+import "testing"
+
+func TestParseForward(t *testing.T) {
+	tests := []struct {
+		spec    string
+		typ     ForwardType
+		listen  string
+		target  string
+		wantErr bool
+	}{
+		{"L:5432:localhost:5432", ForwardLocal, "5432", "localhost:5432", false},
+		{"local:127.0.0.1:8080:web:80", ForwardLocal, "127.0.0.1:8080", "web:80", false},
+		{"R:9000:localhost:9000", ForwardRemote, "9000", "localhost:9000", false},
+		{"remote:[::1]:9000:[2001:db8::1]:22", ForwardRemote, "[::1]:9000", "[2001:db8::1]:22", false},
+		{"D:1080", ForwardDynamic, "1080", "", false},
+		{"dynamic:localhost:1080", ForwardDynamic, "localhost:1080", "", false},
+		{"d:1080", ForwardDynamic, "1080", "", false},
+		{"X:1080", "", "", "", true},
+		{"5432:localhost:5432", "", "", "", true},
+		{"L:5432", "", "", "", true},
+		{"L:abc:localhost:5432", "", "", "", true},
+		{"L:5432:localhost:99999", "", "", "", true},
+		{"D:1080:localhost:22", "", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			f, err := ParseForward(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseForward(%q) should return error, got %+v", tt.spec, f)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseForward(%q) error = %v", tt.spec, err)
+			}
+			if f.Type != tt.typ || f.Listen != tt.listen || f.Target != tt.target {
+				t.Errorf("ParseForward(%q) = %+v", tt.spec, f)
+			}
+		})
+	}
+}
+
+func TestParseForwards(t *testing.T) {
+	forwards, err := ParseForwards("L:5432:localhost:5432, D:1080,")
+	if err != nil {
+		t.Fatalf("ParseForwards() error = %v", err)
+	}
+	if len(forwards) != 2 {
+		t.Fatalf("ParseForwards() returned %d forwards, want 2", len(forwards))
+	}
+
+	if forwards, err := ParseForwards(""); err != nil || len(forwards) != 0 {
+		t.Errorf("ParseForwards(\"\") = %v, %v; want empty", forwards, err)
+	}
+
+	if _, err := ParseForwards("L:5432:localhost:5432, bogus"); err == nil {
+		t.Error("ParseForwards() should return error for an invalid entry")
+	}
+}
+
+func TestForwardStringRoundTrip(t *testing.T) {
+	for _, spec := range []string{"L:5432:localhost:5432", "R:127.0.0.1:9000:localhost:9000", "D:1080"} {
+		f, err := ParseForward(spec)
+		if err != nil {
+			t.Fatalf("ParseForward(%q) error = %v", spec, err)
+		}
+		if f.String() != spec {
+			t.Errorf("String() = %q, want %q", f.String(), spec)
+		}
+	}
+}
+
+func TestForwardValidate(t *testing.T) {
+	valid := Forward{Type: ForwardLocal, Listen: "5432", Target: "db:5432"}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	invalid := []Forward{
+		{Type: "tunnel", Listen: "5432", Target: "db:5432"},
+		{Type: ForwardLocal, Listen: "", Target: "db:5432"},
+		{Type: ForwardLocal, Listen: "5432", Target: "db"},
+		{Type: ForwardLocal, Listen: "5432", Target: ":5432"},
+		{Type: ForwardDynamic, Listen: "1080", Target: "db:22"},
+	}
+	for _, f := range invalid {
+		if err := f.Validate(); err == nil {
+			t.Errorf("Validate(%+v) should return error", f)
+		}
+	}
+}
+
+func TestForwardListenPort(t *testing.T) {
+	tests := []struct {
+		listen string
+		port   int
+	}{
+		{"5432", 5432},
+		{"127.0.0.1:8080", 8080},
+		{"[::1]:9000", 9000},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		f := Forward{Type: ForwardLocal, Listen: tt.listen}
+		if got := f.ListenPort(); got != tt.port {
+			t.Errorf("ListenPort(%q) = %d, want %d", tt.listen, got, tt.port)
+		}
+	}
+}
+
+func TestForwardListenAddress(t *testing.T) {
+	tests := []struct {
+		listen string
+		want   string
+	}{
+		{"5432", "localhost:5432"},
+		{"127.0.0.1:8080", "127.0.0.1:8080"},
+		{"[::1]:9000", "[::1]:9000"},
+		{"*:1080", "localhost:1080"},
+		{":1080", "localhost:1080"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		f := Forward{Type: ForwardLocal, Listen: tt.listen}
+		if got := f.ListenAddress(); got != tt.want {
+			t.Errorf("ListenAddress(%q) = %q, want %q", tt.listen, got, tt.want)
+		}
+	}
+}
@@ -1,7 +1,88 @@
 package config
 
+import "strings"
+
 // Group represents a server group for organization
 type Group struct {
-	Name  string `yaml:"name"`
-	Color string `yaml:"color,omitempty"`
+	Name   string `yaml:"name"`
+	Color  string `yaml:"color,omitempty"`
+	Parent string `yaml:"parent,omitempty"`
+
+	// Connection settings inherited by servers in the group
+	User     string            `yaml:"user,omitempty"`
+	Port     int               `yaml:"port,omitempty"`
+	Key      string            `yaml:"key,omitempty"`
+	Jump     string            `yaml:"jump,omitempty"`
+	Forwards []Forward         `yaml:"forwards,omitempty"`
+	Options  map[string]string `yaml:"options,omitempty"`
+}
+
+// GroupAncestors returns the named group followed by its parents, nearest first.
+// Unknown parents end the chain, and a parent cycle is cut at the repeated group.
+func (c *Config) GroupAncestors(name string) []Group {
+	var chain []Group
+	seen := make(map[string]bool)
+	for name != "" && !seen[name] {
+		g, err := c.FindGroup(name)
+		if err != nil {
+			break
+		}
+		seen[name] = true
+		chain = append(chain, *g)
+		name = g.Parent
+	}
+	return chain
+}
+
+// GroupPath returns the slash separated path of a group, e.g. "prod/eu/web"
+func (c *Config) GroupPath(name string) string {
+	chain := c.GroupAncestors(name)
+	if len(chain) == 0 {
+		return name
+	}
+	parts := make([]string, len(chain))
+	for i, g := range chain {
+		parts[len(chain)-1-i] = g.Name
+	}
+	return strings.Join(parts, "/")
+}
+
+// GroupChildren returns the groups whose parent is the named group.
+// An empty name returns the top-level groups.
+func (c *Config) GroupChildren(name string) []Group {
+	var children []Group
+	for _, g := range c.Groups {
+		parent := g.Parent
+		if parent != "" {
+			if _, err := c.FindGroup(parent); err != nil {
+				// Groups with a missing parent are shown at the top level
+				parent = ""
+			}
+		}
+		if parent == name && g.Name != name {
+			children = append(children, g)
+		}
+	}
+	return children
+}
+
+// InGroup reports whether group is ancestor itself or one of its descendants
+func (c *Config) InGroup(group, ancestor string) bool {
+	for _, g := range c.GroupAncestors(group) {
+		if g.Name == ancestor {
+			return true
+		}
+	}
+	return group == ancestor
+}
+
+// ServersInGroupTree returns servers belonging to a group or any of its descendants
+func (c *Config) ServersInGroupTree(group string) []Server {
+	var servers []Server
+	for _, s := range c.Servers {
+		if s.Group != "" && c.InGroup(s.Group, group) {
+			servers = append(servers, s)
+		}
+	}
+	return servers
 }
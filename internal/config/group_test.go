@@ -23,3 +23,109 @@ func TestGroupEmpty(t *testing.T) {
 		t.Errorf("Empty group Color = %q, want empty", g.Color)
 	}
 }
+
+func newGroupTreeConfig() *Config {
+	return &Config{
+		Groups: []Group{
+			{Name: "prod"},
+			{Name: "eu", Parent: "prod"},
+			{Name: "web", Parent: "eu"},
+			{Name: "us", Parent: "prod"},
+			{Name: "dev"},
+			{Name: "orphan", Parent: "missing"},
+			{Name: "a", Parent: "b"},
+			{Name: "b", Parent: "a"},
+		},
+		Servers: []Server{
+			{Name: "web1", Host: "10.0.0.1", Group: "web"},
+			{Name: "eu1", Host: "10.0.0.2", Group: "eu"},
+			{Name: "us1", Host: "10.0.0.3", Group: "us"},
+			{Name: "dev1", Host: "10.0.0.4", Group: "dev"},
+			{Name: "none", Host: "10.0.0.5"},
+		},
+	}
+}
+
+func TestGroupAncestors(t *testing.T) {
+	cfg := newGroupTreeConfig()
+
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"web", []string{"web", "eu", "prod"}},
+		{"prod", []string{"prod"}},
+		{"orphan", []string{"orphan"}},
+		{"a", []string{"a", "b"}},
+		{"unknown", nil},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := cfg.GroupAncestors(tt.name)
+			if len(chain) != len(tt.want) {
+				t.Fatalf("GroupAncestors(%q) = %v, want %v", tt.name, chain, tt.want)
+			}
+			for i, g := range chain {
+				if g.Name != tt.want[i] {
+					t.Errorf("GroupAncestors(%q)[%d] = %q, want %q", tt.name, i, g.Name, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGroupPath(t *testing.T) {
+	cfg := newGroupTreeConfig()
+
+	tests := map[string]string{
+		"web":     "prod/eu/web",
+		"dev":     "dev",
+		"unknown": "unknown",
+	}
+	for name, want := range tests {
+		if got := cfg.GroupPath(name); got != want {
+			t.Errorf("GroupPath(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestGroupChildren(t *testing.T) {
+	cfg := newGroupTreeConfig()
+
+	var top []string
+	for _, g := range cfg.GroupChildren("") {
+		top = append(top, g.Name)
+	}
+	// Groups with a missing parent are listed at the top level
+	if len(top) != 3 || top[0] != "prod" || top[1] != "dev" || top[2] != "orphan" {
+		t.Errorf("GroupChildren(\"\") = %v, want [prod dev orphan]", top)
+	}
+
+	if children := cfg.GroupChildren("prod"); len(children) != 2 {
+		t.Errorf("GroupChildren(\"prod\") returned %d groups, want 2", len(children))
+	}
+}
+
+func TestServersInGroupTree(t *testing.T) {
+	cfg := newGroupTreeConfig()
+
+	tests := map[string]int{
+		"prod":    3,
+		"eu":      2,
+		"web":     1,
+		"dev":     1,
+		"unknown": 0,
+	}
+	for group, want := range tests {
+		if got := len(cfg.ServersInGroupTree(group)); got != want {
+			t.Errorf("ServersInGroupTree(%q) returned %d servers, want %d", group, got, want)
+		}
+	}
+
+	// ServersByGroup still only returns direct members
+	if got := len(cfg.ServersByGroup("prod")); got != 0 {
+		t.Errorf("ServersByGroup(\"prod\") returned %d servers, want 0", got)
+	}
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// JumpNone disables jump hosts, matching OpenSSH's "ProxyJump none"
+const JumpNone = "none"
+
+// SplitJump splits a comma separated jump specification into its hops
+func SplitJump(spec string) []string {
+	var hops []string
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			hops = append(hops, part)
+		}
+	}
+	return hops
+}
+
+// IsJumpHost reports whether a jump reference is a raw [user@]host[:port]
+// rather than a server name: it has a user, a port or a dot, as every IP
+// address and fully qualified hostname does
+func IsJumpHost(ref string) bool {
+	return strings.ContainsAny(ref, "@:.")
+}
+
+// ValidateJump checks the raw hosts of a jump specification. Server names
+// are checked against a config by CheckJump.
+func ValidateJump(spec string) error {
+	if spec == JumpNone {
+		return nil
+	}
+	for _, ref := range SplitJump(spec) {
+		if IsJumpHost(ref) {
+			if _, err := ParseJumpHost(ref); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// CheckJump checks that every hop of a jump specification is a configured
+// server or a valid raw host
+func (c *Config) CheckJump(spec string) error {
+	if err := ValidateJump(spec); err != nil {
+		return err
+	}
+	if spec == JumpNone {
+		return nil
+	}
+	for _, ref := range SplitJump(spec) {
+		if IsJumpHost(ref) {
+			continue
+		}
+		if _, err := c.FindServer(ref); err != nil {
+			return fmt.Errorf("jump host: %w", c.ServerNotFound(ref))
+		}
+	}
+	return nil
+}
+
+// ParseJumpHost parses a raw [user@]host[:port] jump host.
+// IPv6 addresses with a port must be written as [addr]:port.
+func ParseJumpHost(spec string) (*Server, error) {
+	s := &Server{Name: spec}
+
+	rest := spec
+	if i := strings.LastIndex(rest, "@"); i >= 0 {
+		s.User = rest[:i]
+		rest = rest[i+1:]
+		if s.User == "" {
+			return nil, fmt.Errorf("invalid jump host %q: empty user", spec)
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(rest, "["):
+		host, port, err := net.SplitHostPort(rest)
+		if err != nil {
+			if !strings.HasSuffix(rest, "]") {
+				return nil, fmt.Errorf("invalid jump host %q: %w", spec, err)
+			}
+			host, port = strings.Trim(rest, "[]"), ""
+		}
+		s.Host = host
+		rest = port
+	case strings.Count(rest, ":") == 1:
+		i := strings.Index(rest, ":")
+		s.Host = rest[:i]
+		rest = rest[i+1:]
+	default:
+		// Bare hostname or IPv6 address without a port
+		s.Host = rest
+		rest = ""
+	}
+
+	if rest != "" {
+		port, err := strconv.Atoi(rest)
+		if err != nil {
+			return nil, fmt.Errorf("invalid jump host %q: port must be a number", spec)
+		}
+		if err := ValidatePort(port); err != nil {
+			return nil, fmt.Errorf("invalid jump host %q: %w", spec, err)
+		}
+		s.Port = port
+	}
+
+	if err := ValidateHost(s.Host); err != nil {
+		return nil, fmt.Errorf("invalid jump host %q: %w", spec, err)
+	}
+
+	return s, nil
+}
@@ -0,0 +1,82 @@
+package config
+
+import "testing"
+
+func TestSplitJump(t *testing.T) {
+	hops := SplitJump(" bastion1, ,admin@bastion2:2222 ")
+	if len(hops) != 2 {
+		t.Fatalf("SplitJump() = %v, want 2 hops", hops)
+	}
+	if hops[0] != "bastion1" || hops[1] != "admin@bastion2:2222" {
+		t.Errorf("SplitJump() = %v", hops)
+	}
+
+	if hops := SplitJump(""); len(hops) != 0 {
+		t.Errorf("SplitJump(\"\") = %v, want empty", hops)
+	}
+}
+
+func TestParseJumpHost(t *testing.T) {
+	tests := []struct {
+		spec    string
+		user    string
+		host    string
+		port    int
+		wantErr bool
+	}{
+		{"bastion.example.com", "", "bastion.example.com", 0, false},
+		{"admin@10.0.0.1", "admin", "10.0.0.1", 0, false},
+		{"admin@10.0.0.1:2222", "admin", "10.0.0.1", 2222, false},
+		{"[2001:db8::1]:2222", "", "2001:db8::1", 2222, false},
+		{"root@[2001:db8::1]", "root", "2001:db8::1", 0, false},
+		{"2001:db8::1", "", "2001:db8::1", 0, false},
+		{"host:notaport", "", "", 0, true},
+		{"host:70000", "", "", 0, true},
+		{"@host", "", "", 0, true},
+		{"-invalid", "", "", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			s, err := ParseJumpHost(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseJumpHost(%q) should return error", tt.spec)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseJumpHost(%q) error = %v", tt.spec, err)
+			}
+			if s.User != tt.user || s.Host != tt.host || s.Port != tt.port {
+				t.Errorf("ParseJumpHost(%q) = %s@%s:%d, want %s@%s:%d", tt.spec, s.User, s.Host, s.Port, tt.user, tt.host, tt.port)
+			}
+		})
+	}
+}
+
+func TestCheckJump(t *testing.T) {
+	cfg := &Config{Servers: []Server{{Name: "bastion", Host: "bastion.example.com"}}}
+
+	tests := []struct {
+		spec    string
+		wantErr bool
+	}{
+		{"", false},
+		{JumpNone, false},
+		{"bastion", false},
+		{"admin@gw.example.com:2200,bastion", false},
+		{"10.0.0.1", false},
+		{"bastoin", true},
+		{"gw.example.com:notaport", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			err := cfg.CheckJump(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckJump(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// LookupServer returns the servers a name given on the command line refers
+// to. It tries the exact name, then the name ignoring case, then names
+// starting with it and finally names containing its characters in order (so
+// "webprod" finds "web-prod"), returning the matches of the first step that
+// has any. When nothing matches, the error suggests similar names.
+func (c *Config) LookupServer(name string) ([]Server, error) {
+	s, err := c.FindServer(name)
+	if err == nil {
+		return []Server{*s}, nil
+	}
+	if name == "" {
+		return nil, err
+	}
+
+	query := strings.ToLower(name)
+	steps := []func(candidate string) bool{
+		func(candidate string) bool { return candidate == query },
+		func(candidate string) bool { return strings.HasPrefix(candidate, query) },
+		func(candidate string) bool { return isSubsequence(query, candidate) },
+	}
+	for _, match := range steps {
+		var matches []Server
+		for _, s := range c.Servers {
+			if match(strings.ToLower(s.Name)) {
+				matches = append(matches, s)
+			}
+		}
+		if len(matches) > 0 {
+			return matches, nil
+		}
+	}
+
+	return nil, c.ServerNotFound(name)
+}
+
+// FindServerFold returns the server with the given name or, failing that,
+// the only server whose name matches it ignoring case. Unlike LookupServer
+// it never guesses, for commands that change a server without showing it.
+func (c *Config) FindServerFold(name string) (*Server, error) {
+	if s, err := c.FindServer(name); err == nil {
+		return s, nil
+	}
+
+	var matches []int
+	for i := range c.Servers {
+		if strings.EqualFold(c.Servers[i].Name, name) {
+			matches = append(matches, i)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, c.ServerNotFound(name)
+	case 1:
+		return &c.Servers[matches[0]], nil
+	}
+	names := make([]string, len(matches))
+	for i, m := range matches {
+		names[i] = c.Servers[m].Name
+	}
+	return nil, fmt.Errorf("%q matches several servers ignoring case: %s", name, strings.Join(names, ", "))
+}
+
+// ServerNotFound returns the error for an unknown server name, suggesting
+// similar names when there are any
+func (c *Config) ServerNotFound(name string) error {
+	suggestions := c.suggestServers(strings.ToLower(name))
+	if len(suggestions) == 0 {
+		return fmt.Errorf("server %q not found", name)
+	}
+	quoted := make([]string, len(suggestions))
+	for i, s := range suggestions {
+		quoted[i] = fmt.Sprintf("%q", s)
+	}
+	return fmt.Errorf("server %q not found (did you mean %s?)", name, strings.Join(quoted, " or "))
+}
+
+// maxSuggestions is the most names a "did you mean" hint offers
+const maxSuggestions = 3
+
+// suggestServers returns the names within a few edits of query, closest
+// first. A third of the query's length may differ, and at least two
+// characters so that swapped letters are caught.
+func (c *Config) suggestServers(query string) []string {
+	limit := max(2, len(query)/3)
+
+	type suggestion struct {
+		name     string
+		distance int
+	}
+	var found []suggestion
+	for _, s := range c.Servers {
+		if d := editDistance(query, strings.ToLower(s.Name)); d <= limit {
+			found = append(found, suggestion{s.Name, d})
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].distance < found[j].distance
+	})
+
+	var names []string
+	for i := 0; i < len(found) && i < maxSuggestions; i++ {
+		names = append(names, found[i].name)
+	}
+	return names
+}
+
+// isSubsequence reports whether the characters of s appear in t in order
+func isSubsequence(s, t string) bool {
+	rest := []rune(s)
+	for _, r := range t {
+		if len(rest) == 0 {
+			break
+		}
+		if r == rest[0] {
+			rest = rest[1:]
+		}
+	}
+	return len(rest) == 0
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func newMatchConfig() *Config {
+	return &Config{
+		Servers: []Server{
+			{Name: "web-prod", Host: "10.0.0.1"},
+			{Name: "web-staging", Host: "10.0.0.2"},
+			{Name: "DB", Host: "10.0.0.3"},
+			{Name: "db-replica", Host: "10.0.0.4"},
+			{Name: "bastion", Host: "10.0.0.5"},
+		},
+	}
+}
+
+func TestLookupServer(t *testing.T) {
+	cfg := newMatchConfig()
+
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"web-prod", []string{"web-prod"}},           // exact
+		{"WEB-PROD", []string{"web-prod"}},           // ignoring case
+		{"db", []string{"DB"}},                       // ignoring case beats the prefix of db-replica
+		{"bas", []string{"bastion"}},                 // unique prefix
+		{"web", []string{"web-prod", "web-staging"}}, // several prefixes
+		{"webprod", []string{"web-prod"}},            // fuzzy
+		{"wbstg", []string{"web-staging"}},           // fuzzy
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := cfg.LookupServer(tt.name)
+			if err != nil {
+				t.Fatalf("LookupServer(%q) error = %v", tt.name, err)
+			}
+			var got []string
+			for _, s := range matches {
+				got = append(got, s.Name)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("LookupServer(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookupServerNotFound(t *testing.T) {
+	cfg := newMatchConfig()
+
+	tests := map[string]string{
+		"wbe-prod": `server "wbe-prod" not found (did you mean "web-prod"?)`,
+		"bastoin":  `server "bastoin" not found (did you mean "bastion"?)`,
+		"mail":     `server "mail" not found`,
+		"":         `server "" not found`,
+	}
+	for name, want := range tests {
+		_, err := cfg.LookupServer(name)
+		if err == nil || err.Error() != want {
+			t.Errorf("LookupServer(%q) error = %v, want %s", name, err, want)
+		}
+	}
+}
+
+func TestFindServerFold(t *testing.T) {
+	cfg := newMatchConfig()
+	cfg.Servers = append(cfg.Servers, Server{Name: "Bastion", Host: "10.0.0.6"})
+
+	tests := []struct {
+		name    string
+		want    string
+		wantErr string
+	}{
+		{name: "web-prod", want: "web-prod"},
+		{name: "db", want: "DB"},
+		{name: "bastion", want: "bastion"}, // exact beats ignoring case
+		{name: "BASTION", wantErr: `"BASTION" matches several servers ignoring case: bastion, Bastion`},
+		{name: "web", wantErr: `server "web" not found (did you mean "DB"?)`}, // no prefixes
+		{name: "webprod", wantErr: `server "webprod" not found (did you mean "web-prod"?)`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := cfg.FindServerFold(tt.name)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("FindServerFold(%q) error = %v, want %s", tt.name, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FindServerFold(%q) error = %v", tt.name, err)
+			}
+			if s.Name != tt.want {
+				t.Errorf("FindServerFold(%q) = %q, want %q", tt.name, s.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"kitten", "sitting", 3},
+		{"web-prod", "wbe-prod", 2},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
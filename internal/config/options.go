@@ -0,0 +1,79 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MergeOptions combines ssh option layers, later layers taking precedence.
+// Option names are matched case-insensitively, as ssh does.
+func MergeOptions(layers ...map[string]string) map[string]string {
+	var merged map[string]string
+	for _, layer := range layers {
+		for key, value := range layer {
+			if merged == nil {
+				merged = make(map[string]string)
+			}
+			for existing := range merged {
+				if strings.EqualFold(existing, key) {
+					delete(merged, existing)
+				}
+			}
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
+// LookupOption returns the value of an option, matching its name
+// case-insensitively
+func LookupOption(options map[string]string, key string) (string, bool) {
+	for name, value := range options {
+		if strings.EqualFold(name, key) {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// SortedOptionKeys returns option names in a stable order
+func SortedOptionKeys(options map[string]string) []string {
+	keys := make([]string, 0, len(options))
+	for key := range options {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return strings.ToLower(keys[i]) < strings.ToLower(keys[j])
+	})
+	return keys
+}
+
+// ParseOption parses a Key=Value option as given to ssh -o
+func ParseOption(s string) (string, string, error) {
+	key, value, ok := strings.Cut(s, "=")
+	key = strings.TrimSpace(key)
+	if !ok {
+		// ssh also accepts "Key Value"
+		key, value, ok = strings.Cut(strings.TrimSpace(s), " ")
+	}
+	if !ok {
+		return "", "", fmt.Errorf("invalid option %q (use Key=Value)", s)
+	}
+	value = strings.TrimSpace(value)
+	if err := ValidateOption(key, value); err != nil {
+		return "", "", err
+	}
+	return key, value, nil
+}
+
+// ValidateOption checks that an option name and value can be passed to ssh -o
+func ValidateOption(key, value string) error {
+	if key == "" || strings.ContainsAny(key, " \t=") {
+		return fmt.Errorf("invalid option name %q", key)
+	}
+	if value == "" {
+		return fmt.Errorf("option %s has no value", key)
+	}
+	return nil
+}
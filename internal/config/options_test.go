@@ -0,0 +1,97 @@
+package config
+
+import "testing"
+
+func TestMergeOptions(t *testing.T) {
+	defaults := map[string]string{"ServerAliveInterval": "60", "ForwardAgent": "no"}
+	group := map[string]string{"forwardagent": "yes"}
+	server := map[string]string{"StrictHostKeyChecking": "no"}
+
+	merged := MergeOptions(defaults, group, nil, server)
+	if len(merged) != 3 {
+		t.Fatalf("MergeOptions() = %v, want 3 options", merged)
+	}
+	if merged["forwardagent"] != "yes" {
+		t.Errorf("forwardagent = %q, want %q (later layer wins)", merged["forwardagent"], "yes")
+	}
+	if _, ok := merged["ForwardAgent"]; ok {
+		t.Error("MergeOptions() should replace options case-insensitively")
+	}
+	if merged["ServerAliveInterval"] != "60" || merged["StrictHostKeyChecking"] != "no" {
+		t.Errorf("MergeOptions() = %v", merged)
+	}
+
+	if merged := MergeOptions(nil, map[string]string{}); merged != nil {
+		t.Errorf("MergeOptions() of empty layers = %v, want nil", merged)
+	}
+}
+
+func TestSortedOptionKeys(t *testing.T) {
+	keys := SortedOptionKeys(map[string]string{"b": "1", "A": "2", "c": "3"})
+	if len(keys) != 3 || keys[0] != "A" || keys[1] != "b" || keys[2] != "c" {
+		t.Errorf("SortedOptionKeys() = %v, want [A b c]", keys)
+	}
+}
+
+func TestLookupOption(t *testing.T) {
+	options := map[string]string{"ConnectTimeout": "10"}
+	if v, ok := LookupOption(options, "connecttimeout"); !ok || v != "10" {
+		t.Errorf("LookupOption(connecttimeout) = %q, %v, want 10, true", v, ok)
+	}
+	if _, ok := LookupOption(options, "Port"); ok {
+		t.Error("LookupOption(Port) found a missing option")
+	}
+}
+
+func TestParseOption(t *testing.T) {
+	tests := []struct {
+		in      string
+		key     string
+		value   string
+		wantErr bool
+	}{
+		{"ServerAliveInterval=30", "ServerAliveInterval", "30", false},
+		{"ForwardAgent yes", "ForwardAgent", "yes", false},
+		{"ProxyCommand=ssh -W %h:%p gw", "ProxyCommand", "ssh -W %h:%p gw", false},
+		{"NoValue", "", "", true},
+		{"Empty=", "", "", true},
+		{"=value", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			key, value, err := ParseOption(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseOption(%q) should return error", tt.in)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseOption(%q) error = %v", tt.in, err)
+			}
+			if key != tt.key || value != tt.value {
+				t.Errorf("ParseOption(%q) = %q, %q; want %q, %q", tt.in, key, value, tt.key, tt.value)
+			}
+		})
+	}
+}
+
+func TestLoadOptionsFromYAML(t *testing.T) {
+	cfg := loadTestConfig(t, `defaults:
+  options:
+    ServerAliveInterval: 30
+servers:
+  - name: web
+    host: example.com
+    options:
+      ForwardAgent: yes
+`)
+
+	if cfg.Defaults.Options["ServerAliveInterval"] != "30" {
+		t.Errorf("Defaults.Options = %v", cfg.Defaults.Options)
+	}
+	if cfg.Servers[0].Options["ForwardAgent"] != "yes" {
+		t.Errorf("Server options = %v", cfg.Servers[0].Options)
+	}
+}
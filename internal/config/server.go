@@ -1,6 +1,10 @@
 package config
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
 
 // Server represents an SSH server configuration
 type Server struct {
@@ -10,6 +14,22 @@ type Server struct {
 	Port  int    `yaml:"port,omitempty"`
 	Key   string `yaml:"key,omitempty"`
 	Group string `yaml:"group,omitempty"`
+	Jump  string `yaml:"jump,omitempty"`
+
+	Transport string `yaml:"transport,omitempty"` // openssh or native, see TransportOpenSSH
+
+	Tags     []string          `yaml:"tags,omitempty"`
+	Forwards []Forward         `yaml:"forwards,omitempty"`
+	Options  map[string]string `yaml:"options,omitempty"`
+
+	// JumpChain holds the resolved jump hosts, first hop first.
+	// It is filled in during resolution and never persisted.
+	JumpChain []Server `yaml:"-"`
+
+	// ExtraArgs and Command come from the command line for a single
+	// connection: ssh arguments and a remote command. Never persisted.
+	ExtraArgs []string `yaml:"-"`
+	Command   string   `yaml:"-"`
 }
 
 // FilterValue implements list.Item for bubbles list
@@ -33,3 +53,70 @@ func (s Server) Description() string {
 	}
 	return desc
 }
+
+// SetFields lists the fields accepted by Set
+var SetFields = []string{"name", "host", "user", "port", "key", "group", "jump", "transport", "tags", "forwards", "options.<Key>"}
+
+// Set assigns a field by its config name, as given to edit --set. An empty
+// value clears the field. tags and forwards take comma separated lists and
+// options.<Key> sets or, when empty, removes a single ssh option.
+func (s *Server) Set(field, value string) error {
+	value = strings.TrimSpace(value)
+	switch strings.ToLower(field) {
+	case "name":
+		s.Name = value
+	case "host":
+		s.Host = value
+	case "user":
+		s.User = value
+	case "port":
+		s.Port = 0
+		if value != "" {
+			port, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("port must be a number, got %q", value)
+			}
+			s.Port = port
+		}
+	case "key":
+		s.Key = value
+	case "group":
+		s.Group = value
+	case "jump":
+		s.Jump = value
+	case "transport":
+		s.Transport = value
+	case "tags":
+		tags, err := ParseTags(value)
+		if err != nil {
+			return err
+		}
+		s.Tags = tags
+	case "forwards":
+		forwards, err := ParseForwards(value)
+		if err != nil {
+			return err
+		}
+		s.Forwards = forwards
+	default:
+		key, ok := strings.CutPrefix(field, "options.")
+		if !ok || key == "" {
+			return fmt.Errorf("unknown field %q (use one of %s)", field, strings.Join(SetFields, ", "))
+		}
+		// Build a new map: s may be a copy sharing its options with the original
+		options := make(map[string]string)
+		for existing, v := range s.Options {
+			if !strings.EqualFold(existing, key) {
+				options[existing] = v
+			}
+		}
+		if value != "" {
+			options[key] = value
+		}
+		s.Options = options
+		if len(options) == 0 {
+			s.Options = nil
+		}
+	}
+	return nil
+}
@@ -1,6 +1,9 @@
 package config
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestServerFilterValue(t *testing.T) {
 	s := Server{Name: "web1", Host: "192.168.1.1", Group: "production"}
@@ -59,3 +62,42 @@ func TestServerDescription(t *testing.T) {
 		})
 	}
 }
+
+func TestServerSet(t *testing.T) {
+	s := Server{Name: "web1", Host: "10.0.0.1", Port: 2222, Options: map[string]string{"ServerAliveInterval": "30"}}
+
+	sets := [][2]string{
+		{"host", "web1.example.com"},
+		{"User", " deploy "},
+		{"port", ""},
+		{"tags", "web, prod"},
+		{"forwards", "L:8080:localhost:80,D:1080"},
+		{"options.serveraliveinterval", "60"},
+		{"options.ForwardAgent", "yes"},
+		{"options.ForwardAgent", ""},
+	}
+	for _, set := range sets {
+		if err := s.Set(set[0], set[1]); err != nil {
+			t.Fatalf("Set(%q, %q) error = %v", set[0], set[1], err)
+		}
+	}
+
+	if s.Host != "web1.example.com" || s.User != "deploy" || s.Port != 0 {
+		t.Errorf("Set() host, user, port = %q, %q, %d", s.Host, s.User, s.Port)
+	}
+	if len(s.Tags) != 2 || s.Tags[1] != "prod" {
+		t.Errorf("Set(tags) = %v, want [web prod]", s.Tags)
+	}
+	if len(s.Forwards) != 2 || s.Forwards[1].Type != ForwardDynamic {
+		t.Errorf("Set(forwards) = %v", s.Forwards)
+	}
+	if len(s.Options) != 1 || s.Options["serveraliveinterval"] != "60" {
+		t.Errorf("Set(options) = %v, want only serveraliveinterval=60", s.Options)
+	}
+
+	for _, set := range [][2]string{{"port", "ssh"}, {"tags", strings.Repeat("a", 65)}, {"forwards", "X:1"}, {"color", "red"}, {"options.", "x"}} {
+		if err := s.Set(set[0], set[1]); err == nil {
+			t.Errorf("Set(%q, %q) expected error, got nil", set[0], set[1])
+		}
+	}
+}
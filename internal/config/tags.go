@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseTags parses a comma or whitespace separated list of tags,
+// dropping duplicates (compared case-insensitively)
+func ParseTags(s string) ([]string, error) {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, tag := range strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	}) {
+		if err := ValidateTag(tag); err != nil {
+			return nil, err
+		}
+		if seen[strings.ToLower(tag)] {
+			continue
+		}
+		seen[strings.ToLower(tag)] = true
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// ValidateTag checks that a tag is non-empty and free of separators
+func ValidateTag(tag string) error {
+	if tag == "" {
+		return fmt.Errorf("tag cannot be empty")
+	}
+	if len(tag) > 64 {
+		return fmt.Errorf("tag %q too long (max 64 characters)", tag)
+	}
+	if strings.ContainsAny(tag, ", \t\n") {
+		return fmt.Errorf("tag %q cannot contain commas or whitespace", tag)
+	}
+	return nil
+}
+
+// HasTag reports whether the server carries tag, ignoring case
+func (s Server) HasTag(tag string) bool {
+	for _, t := range s.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchTags reports whether the server carries all of tags when matchAll is
+// set, or any of them otherwise. An empty tag list matches every server.
+func (s Server) MatchTags(tags []string, matchAll bool) bool {
+	if len(tags) == 0 {
+		return true
+	}
+	for _, tag := range tags {
+		has := s.HasTag(tag)
+		if has && !matchAll {
+			return true
+		}
+		if !has && matchAll {
+			return false
+		}
+	}
+	return matchAll
+}
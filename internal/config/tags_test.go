@@ -0,0 +1,84 @@
+package config
+
+import "testing"
+
+func TestParseTags(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"db", []string{"db"}, false},
+		{"db, k8s-node legacy", []string{"db", "k8s-node", "legacy"}, false},
+		{"db,DB,db", []string{"db"}, false},
+		{" , ", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseTags(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTags(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseTags(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("ParseTags(%q)[%d] = %q, want %q", tt.input, i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateTag(t *testing.T) {
+	tests := []struct {
+		tag     string
+		wantErr bool
+	}{
+		{"db", false},
+		{"k8s-node", false},
+		{"team:payments", false},
+		{"", true},
+		{"two words", true},
+		{"a,b", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			err := ValidateTag(tt.tag)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateTag(%q) error = %v, wantErr %v", tt.tag, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestServerMatchTags(t *testing.T) {
+	s := Server{Name: "db1", Tags: []string{"db", "Legacy"}}
+
+	tests := []struct {
+		name     string
+		tags     []string
+		matchAll bool
+		want     bool
+	}{
+		{"no tags", nil, false, true},
+		{"any single", []string{"db"}, false, true},
+		{"any case insensitive", []string{"legacy"}, false, true},
+		{"any one of", []string{"web", "db"}, false, true},
+		{"any none", []string{"web", "k8s"}, false, false},
+		{"all present", []string{"db", "legacy"}, true, true},
+		{"all missing one", []string{"db", "web"}, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.MatchTags(tt.tags, tt.matchAll); got != tt.want {
+				t.Errorf("MatchTags(%v, %v) = %v, want %v", tt.tags, tt.matchAll, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package config
+
+import "fmt"
+
+// Transports select how sshto talks to a server
+const (
+	TransportOpenSSH = "openssh" // run the ssh binary (the default)
+	TransportNative  = "native"  // built-in Go client, for systems without OpenSSH
+)
+
+// ValidateTransport checks that a transport name is known. Empty means the default.
+func ValidateTransport(transport string) error {
+	switch transport {
+	case "", TransportOpenSSH, TransportNative:
+		return nil
+	}
+	return fmt.Errorf("invalid transport %q (use %s or %s)", transport, TransportOpenSSH, TransportNative)
+}
@@ -111,5 +111,26 @@ func ValidateServer(s *Server) error {
 	if err := ValidatePort(s.Port); err != nil {
 		return err
 	}
+	if err := ValidateTransport(s.Transport); err != nil {
+		return err
+	}
+	if err := ValidateJump(s.Jump); err != nil {
+		return err
+	}
+	for _, tag := range s.Tags {
+		if err := ValidateTag(tag); err != nil {
+			return err
+		}
+	}
+	for _, f := range s.Forwards {
+		if err := f.Validate(); err != nil {
+			return err
+		}
+	}
+	for key, value := range s.Options {
+		if err := ValidateOption(key, value); err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -221,6 +221,16 @@ func TestValidateServer(t *testing.T) {
 			&Server{Name: "test", Host: "192.168.1.1", Port: 70000},
 			true,
 		},
+		{
+			"native transport",
+			&Server{Name: "test", Host: "192.168.1.1", Transport: TransportNative},
+			false,
+		},
+		{
+			"invalid transport",
+			&Server{Name: "test", Host: "192.168.1.1", Transport: "telnet"},
+			true,
+		},
 	}
 
 	for _, tt := range tests {
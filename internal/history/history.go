@@ -0,0 +1,227 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/codoworks/sshto/internal/ssh"
+)
+
+// MaxEntries is the number of connections kept in the history file
+const MaxEntries = 500
+
+const (
+	lockWait  = 2 * time.Second  // how long Update waits for another sshto to release the lock
+	lockStale = 10 * time.Second // age at which a lock is taken to be left by a process that died
+)
+
+// Entry records a single connection
+type Entry struct {
+	Server   string        `yaml:"server"`
+	Time     time.Time     `yaml:"time"`
+	Duration time.Duration `yaml:"duration"`
+	ExitCode int           `yaml:"exit_code"`
+}
+
+// Last is the most recent connection with the overrides it was made with
+type Last struct {
+	Server    string             `yaml:"server"`
+	Time      time.Time          `yaml:"time"`
+	Overrides ssh.ConnectOptions `yaml:"overrides,omitempty"`
+}
+
+// Store holds the connection history, oldest entry first
+type Store struct {
+	Last    *Last   `yaml:"last,omitempty"`
+	Entries []Entry `yaml:"entries"`
+
+	path string // internal: path to history file
+}
+
+// Path returns the history file path next to the given config file
+func Path(configPath string) string {
+	return filepath.Join(filepath.Dir(configPath), "history.yaml")
+}
+
+// New returns an empty history to be saved at path
+func New(path string) *Store {
+	return &Store{path: path}
+}
+
+// Load reads the history from the given path. A missing file yields an empty history.
+func Load(path string) (*Store, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return New(path), nil
+		}
+		return nil, fmt.Errorf("reading history: %w", err)
+	}
+
+	var s Store
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parsing history: %w", err)
+	}
+
+	s.path = path
+	return &s, nil
+}
+
+// Save writes the history to disk
+func (s *Store) Save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("creating history directory: %w", err)
+	}
+
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("marshaling history: %w", err)
+	}
+
+	// Replace the file atomically so concurrent sessions never read a partial write
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("writing history: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("writing history: %w", err)
+	}
+
+	return nil
+}
+
+// Update applies fn to the history on disk and saves the result, holding a
+// lock so that sessions running side by side keep each other's changes. A
+// damaged file is replaced by fn applied to an empty history.
+func Update(path string, fn func(*Store)) (*Store, error) {
+	unlock, err := lock(path)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	s, err := Load(path)
+	if err != nil {
+		s = New(path)
+	}
+	fn(s)
+	if err := s.Save(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// lock creates the lock file next to the history, waiting for another sshto
+// holding it. It returns the function that releases the lock.
+func lock(path string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("creating history directory: %w", err)
+	}
+
+	name := path + ".lock"
+	deadline := time.Now().Add(lockWait)
+	for {
+		f, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(name) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("locking history: %w", err)
+		}
+		if info, err := os.Stat(name); err == nil && time.Since(info.ModTime()) > lockStale {
+			os.Remove(name)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("locking history: %s is held by another sshto", name)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// Path returns the history file path
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Add appends an entry, dropping the oldest entries beyond MaxEntries
+func (s *Store) Add(e Entry) {
+	s.Entries = append(s.Entries, e)
+	if len(s.Entries) > MaxEntries {
+		s.Entries = append([]Entry(nil), s.Entries[len(s.Entries)-MaxEntries:]...)
+	}
+}
+
+// Clear removes all entries and the last connection
+func (s *Store) Clear() {
+	s.Last = nil
+	s.Entries = nil
+}
+
+// Recent returns up to limit entries, newest first, optionally for a single server.
+// A limit of zero or less returns every matching entry.
+func (s *Store) Recent(server string, limit int) []Entry {
+	var recent []Entry
+	for i := len(s.Entries) - 1; i >= 0; i-- {
+		if server != "" && s.Entries[i].Server != server {
+			continue
+		}
+		recent = append(recent, s.Entries[i])
+		if limit > 0 && len(recent) == limit {
+			break
+		}
+	}
+	return recent
+}
+
+// Scores returns a frecency score per server: every connection counts,
+// weighted by how recently it happened
+func (s *Store) Scores(now time.Time) map[string]float64 {
+	scores := make(map[string]float64)
+	for _, e := range s.Entries {
+		scores[e.Server] += recencyWeight(now.Sub(e.Time))
+	}
+	return scores
+}
+
+// recencyWeight returns the score of a single connection made age ago
+func recencyWeight(age time.Duration) float64 {
+	const day = 24 * time.Hour
+	switch {
+	case age < 4*time.Hour:
+		return 100
+	case age < day:
+		return 80
+	case age < 7*day:
+		return 60
+	case age < 30*day:
+		return 30
+	case age < 90*day:
+		return 10
+	default:
+		return 1
+	}
+}
+
+// Ranked returns server names ordered by score, highest first, ties by name
+func Ranked(scores map[string]float64) []string {
+	names := make([]string, 0, len(scores))
+	for name := range scores {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if scores[names[i]] != scores[names[j]] {
+			return scores[names[i]] > scores[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
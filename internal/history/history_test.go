@@ -0,0 +1,224 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/codoworks/sshto/internal/ssh"
+)
+
+func TestPath(t *testing.T) {
+	got := Path(filepath.Join("home", ".config", "sshto", "config.yaml"))
+	want := filepath.Join("home", ".config", "sshto", "history.yaml")
+	if got != want {
+		t.Errorf("Path() = %q, want %q", got, want)
+	}
+}
+
+func TestLoadNonExistentFile(t *testing.T) {
+	s, err := Load("/nonexistent/path/history.yaml")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(s.Entries) != 0 {
+		t.Errorf("Entries = %d, want 0", len(s.Entries))
+	}
+}
+
+func TestLoadAndSave(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sshto-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	path := filepath.Join(tmpDir, "state", "history.yaml")
+	s, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	at := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	s.Add(Entry{Server: "web1", Time: at, Duration: 90 * time.Second, ExitCode: 0})
+	s.Add(Entry{Server: "db1", Time: at.Add(time.Hour), Duration: time.Second, ExitCode: 255})
+	s.Last = &Last{Server: "db1", Time: at, Overrides: ssh.ConnectOptions{User: "root", Port: 2222}}
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(loaded.Entries) != 2 {
+		t.Fatalf("Entries = %d, want 2", len(loaded.Entries))
+	}
+	e := loaded.Entries[1]
+	if e.Server != "db1" || !e.Time.Equal(at.Add(time.Hour)) || e.Duration != time.Second || e.ExitCode != 255 {
+		t.Errorf("Entries[1] = %+v", e)
+	}
+	if loaded.Last == nil || loaded.Last.Server != "db1" || loaded.Last.Overrides.User != "root" || loaded.Last.Overrides.Port != 2222 {
+		t.Errorf("Last = %+v", loaded.Last)
+	}
+
+	loaded.Clear()
+	if loaded.Last != nil || loaded.Entries != nil {
+		t.Error("Clear() should remove entries and the last connection")
+	}
+}
+
+func TestLoadInvalidYAML(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sshto-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	path := filepath.Join(tmpDir, "history.yaml")
+	if err := os.WriteFile(path, []byte("entries: [unclosed"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	if _, err := Load(path); err == nil {
+		t.Error("Load() should return error for invalid YAML")
+	}
+}
+
+func TestUpdateConcurrent(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sshto-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// Sessions started side by side each add their entry to the file as it is then
+	path := filepath.Join(tmpDir, "history.yaml")
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := Update(path, func(s *Store) { s.Add(Entry{Server: fmt.Sprintf("web%d", i)}) }); err != nil {
+				t.Errorf("Update() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	s, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(s.Entries) != 10 {
+		t.Errorf("Entries = %d, want 10", len(s.Entries))
+	}
+	if _, err := os.Stat(path + ".lock"); !os.IsNotExist(err) {
+		t.Errorf("lock file left behind: %v", err)
+	}
+}
+
+func TestUpdateDamagedFile(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sshto-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	path := filepath.Join(tmpDir, "history.yaml")
+	if err := os.WriteFile(path, []byte("entries: [unclosed"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	s, err := Update(path, func(s *Store) { s.Add(Entry{Server: "web1"}) })
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if len(s.Entries) != 1 || s.Entries[0].Server != "web1" {
+		t.Errorf("Entries = %+v, want only web1", s.Entries)
+	}
+}
+
+func TestUpdateStaleLock(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sshto-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// A lock left by a process that died is taken over
+	path := filepath.Join(tmpDir, "history.yaml")
+	if err := os.WriteFile(path+".lock", nil, 0644); err != nil {
+		t.Fatalf("Failed to write lock: %v", err)
+	}
+	old := time.Now().Add(-time.Minute)
+	if err := os.Chtimes(path+".lock", old, old); err != nil {
+		t.Fatalf("Failed to age lock: %v", err)
+	}
+
+	if _, err := Update(path, (*Store).Clear); err != nil {
+		t.Errorf("Update() error = %v", err)
+	}
+}
+
+func TestAddCapsEntries(t *testing.T) {
+	s := &Store{}
+	for i := 0; i < MaxEntries+10; i++ {
+		s.Add(Entry{Server: "web1", ExitCode: i})
+	}
+
+	if len(s.Entries) != MaxEntries {
+		t.Fatalf("Entries = %d, want %d", len(s.Entries), MaxEntries)
+	}
+	if s.Entries[0].ExitCode != 10 {
+		t.Errorf("oldest entry = %d, want 10", s.Entries[0].ExitCode)
+	}
+}
+
+func TestRecent(t *testing.T) {
+	s := &Store{Entries: []Entry{
+		{Server: "web1", ExitCode: 1},
+		{Server: "db1", ExitCode: 2},
+		{Server: "web1", ExitCode: 3},
+	}}
+
+	recent := s.Recent("", 2)
+	if len(recent) != 2 || recent[0].ExitCode != 3 || recent[1].ExitCode != 2 {
+		t.Errorf("Recent(\"\", 2) = %+v", recent)
+	}
+
+	recent = s.Recent("web1", 0)
+	if len(recent) != 2 || recent[0].ExitCode != 3 || recent[1].ExitCode != 1 {
+		t.Errorf("Recent(\"web1\", 0) = %+v", recent)
+	}
+}
+
+func TestScores(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	s := &Store{Entries: []Entry{
+		// Often, but months ago
+		{Server: "old", Time: now.Add(-200 * 24 * time.Hour)},
+		{Server: "old", Time: now.Add(-200 * 24 * time.Hour)},
+		{Server: "old", Time: now.Add(-200 * 24 * time.Hour)},
+		// Once, just now
+		{Server: "recent", Time: now.Add(-time.Minute)},
+		// Several times this week
+		{Server: "regular", Time: now.Add(-2 * time.Hour)},
+		{Server: "regular", Time: now.Add(-30 * time.Hour)},
+		{Server: "regular", Time: now.Add(-50 * time.Hour)},
+	}}
+
+	ranked := Ranked(s.Scores(now))
+	want := []string{"regular", "recent", "old"}
+	if len(ranked) != len(want) {
+		t.Fatalf("Ranked() = %v, want %v", ranked, want)
+	}
+	for i := range want {
+		if ranked[i] != want[i] {
+			t.Errorf("Ranked()[%d] = %q, want %q", i, ranked[i], want[i])
+		}
+	}
+}
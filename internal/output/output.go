@@ -0,0 +1,120 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format is an output format accepted by --output
+type Format string
+
+const (
+	Table    Format = "table"
+	JSON     Format = "json"
+	YAML     Format = "yaml"
+	Names    Format = "names"
+	Template Format = "template"
+)
+
+// Formats lists the formats in the order they are documented
+var Formats = []Format{Table, JSON, YAML, Names, Template}
+
+// ParseFormat returns the format with the given name
+func ParseFormat(s string) (Format, error) {
+	for _, f := range Formats {
+		if strings.EqualFold(s, string(f)) {
+			return f, nil
+		}
+	}
+	names := make([]string, len(Formats))
+	for i, f := range Formats {
+		names[i] = string(f)
+	}
+	return "", fmt.Errorf("invalid output format %q (use %s)", s, strings.Join(names, ", "))
+}
+
+// View describes how records of type T are shown as a table and as names.
+// JSON, YAML and templates use the records themselves.
+type View[T any] struct {
+	Header []string
+	Row    func(T) []string
+	Name   func(T) string
+}
+
+// Print writes records to w in the given format. tmpl is the Go template
+// for the template format, executed once per record.
+func (v View[T]) Print(w io.Writer, format Format, tmpl string, records []T) error {
+	switch format {
+	case Table:
+		return v.printTable(w, records)
+	case JSON:
+		if records == nil {
+			records = []T{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+	case YAML:
+		if records == nil {
+			records = []T{}
+		}
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(records); err != nil {
+			return err
+		}
+		return enc.Close()
+	case Names:
+		for _, r := range records {
+			if _, err := fmt.Fprintln(w, v.Name(r)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case Template:
+		return printTemplate(w, tmpl, records)
+	}
+	return fmt.Errorf("invalid output format %q", format)
+}
+
+// printTable writes the records as aligned columns, with "-" for empty cells
+func (v View[T]) printTable(w io.Writer, records []T) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(v.Header, "\t"))
+	for _, r := range records {
+		row := v.Row(r)
+		for i, cell := range row {
+			if cell == "" {
+				row[i] = "-"
+			}
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// printTemplate executes tmpl for each record, each on its own line
+func printTemplate[T any](w io.Writer, tmpl string, records []T) error {
+	if tmpl == "" {
+		return fmt.Errorf("the template format needs a template, e.g. --template '{{.Name}}'")
+	}
+	t, err := template.New("output").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return fmt.Errorf("parsing template: %w", err)
+	}
+	for _, r := range records {
+		if err := t.Execute(w, r); err != nil {
+			return fmt.Errorf("executing template: %w", err)
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
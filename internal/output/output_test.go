@@ -0,0 +1,103 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+type record struct {
+	Name string   `json:"name" yaml:"name"`
+	Host string   `json:"host" yaml:"host"`
+	Tags []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+}
+
+var view = View[record]{
+	Header: []string{"NAME", "HOST", "TAGS"},
+	Row: func(r record) []string {
+		return []string{r.Name, r.Host, strings.Join(r.Tags, ",")}
+	},
+	Name: func(r record) string { return r.Name },
+}
+
+var records = []record{
+	{Name: "web1", Host: "10.0.0.1", Tags: []string{"web", "prod"}},
+	{Name: "db1", Host: "10.0.0.2"},
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, name := range []string{"table", "JSON", "yaml", "names", "template"} {
+		if _, err := ParseFormat(name); err != nil {
+			t.Errorf("ParseFormat(%q) error = %v", name, err)
+		}
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("ParseFormat(\"xml\") expected error, got nil")
+	}
+}
+
+func TestPrint(t *testing.T) {
+	tests := []struct {
+		format Format
+		tmpl   string
+		want   string
+	}{
+		{Table, "", "NAME  HOST      TAGS\nweb1  10.0.0.1  web,prod\ndb1   10.0.0.2  -\n"},
+		{JSON, "", `[
+  {
+    "name": "web1",
+    "host": "10.0.0.1",
+    "tags": [
+      "web",
+      "prod"
+    ]
+  },
+  {
+    "name": "db1",
+    "host": "10.0.0.2"
+  }
+]
+`},
+		{YAML, "", `- name: web1
+  host: 10.0.0.1
+  tags:
+    - web
+    - prod
+- name: db1
+  host: 10.0.0.2
+`},
+		{Names, "", "web1\ndb1\n"},
+		{Template, "{{.Name}}={{.Host}}", "web1=10.0.0.1\ndb1=10.0.0.2\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := view.Print(&buf, tt.format, tt.tmpl, records); err != nil {
+				t.Fatalf("Print() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("Print() =\n%s\nwant\n%s", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := view.Print(&buf, JSON, "", nil); err != nil {
+		t.Fatalf("Print() error = %v", err)
+	}
+	if buf.String() != "[]\n" {
+		t.Errorf("Print(JSON, nil) = %q, want %q", buf.String(), "[]\n")
+	}
+}
+
+func TestPrintTemplateErrors(t *testing.T) {
+	var buf bytes.Buffer
+	for _, tmpl := range []string{"", "{{.Name", "{{.Missing}}"} {
+		if err := view.Print(&buf, Template, tmpl, records); err == nil {
+			t.Errorf("Print(Template, %q) expected error, got nil", tmpl)
+		}
+	}
+}
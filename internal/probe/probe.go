@@ -0,0 +1,116 @@
+package probe
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// State is the outcome of a reachability check
+type State string
+
+const (
+	StateReachable  State = "reachable"
+	StateAuthFailed State = "auth-failed"
+	StateHostKey    State = "host-key-failed"
+	StateTimeout    State = "timeout"
+	StateRefused    State = "refused"
+	StateDNSFailure State = "dns-failure"
+	StateError      State = "error"
+)
+
+// Result is the outcome of checking one server
+type Result struct {
+	Server  string
+	Address string // host:port that was dialed
+	State   State
+	Latency time.Duration // time to establish the TCP connection
+	Detail  string
+}
+
+// OK reports whether the server was reachable
+func (r Result) OK() bool {
+	return r.State == StateReachable
+}
+
+// Dial opens and immediately closes a TCP connection to address
+func Dial(ctx context.Context, address string, timeout time.Duration) Result {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var d net.Dialer
+	start := time.Now()
+	conn, err := d.DialContext(ctx, "tcp", address)
+	latency := time.Since(start)
+	if err != nil {
+		return Result{Address: address, State: Classify(err), Detail: err.Error()}
+	}
+	conn.Close()
+
+	return Result{Address: address, State: StateReachable, Latency: latency}
+}
+
+// Classify maps a dial error to a State
+func Classify(err error) State {
+	if err == nil {
+		return StateReachable
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		if dnsErr.IsTimeout {
+			return StateTimeout
+		}
+		return StateDNSFailure
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded) ||
+		(errors.As(err, &netErr) && netErr.Timeout()) {
+		return StateTimeout
+	}
+
+	// Windows reports WSAECONNREFUSED, which is not syscall.ECONNREFUSED
+	if errors.Is(err, syscall.ECONNREFUSED) || strings.Contains(err.Error(), "refused") {
+		return StateRefused
+	}
+
+	return StateError
+}
+
+// sshFailures maps ssh error messages to states, checked in order
+var sshFailures = []struct {
+	message string
+	state   State
+}{
+	{"Permission denied", StateAuthFailed},
+	{"Too many authentication failures", StateAuthFailed},
+	{"Host key verification failed", StateHostKey},
+	{"REMOTE HOST IDENTIFICATION HAS CHANGED", StateHostKey},
+	{"Could not resolve hostname", StateDNSFailure},
+	{"timed out", StateTimeout},
+	{"Connection refused", StateRefused},
+}
+
+// ClassifySSH maps the output of a failed ssh connection test to a State
+// and the line of output that explains it
+func ClassifySSH(output string) (State, string) {
+	for _, f := range sshFailures {
+		for _, line := range strings.Split(output, "\n") {
+			if strings.Contains(line, f.message) {
+				return f.state, strings.TrimSpace(line)
+			}
+		}
+	}
+	return StateError, lastLine(output)
+}
+
+// lastLine returns the last non-empty line of s
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
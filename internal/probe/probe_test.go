@@ -0,0 +1,92 @@
+package probe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestDialReachable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer ln.Close()
+
+	res := Dial(context.Background(), ln.Addr().String(), time.Second)
+	if res.State != StateReachable || !res.OK() {
+		t.Errorf("Dial() = %+v, want reachable", res)
+	}
+	if res.Address != ln.Addr().String() {
+		t.Errorf("Address = %q, want %q", res.Address, ln.Addr().String())
+	}
+}
+
+func TestDialRefused(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	res := Dial(context.Background(), addr, time.Second)
+	if res.State != StateRefused || res.OK() {
+		t.Errorf("Dial() = %+v, want refused", res)
+	}
+	if res.Detail == "" {
+		t.Error("Detail should explain the failure")
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want State
+	}{
+		{"nil", nil, StateReachable},
+		{"dns", &net.OpError{Op: "dial", Err: &net.DNSError{Err: "no such host", Name: "nope.invalid", IsNotFound: true}}, StateDNSFailure},
+		{"dns timeout", &net.DNSError{Err: "i/o timeout", IsTimeout: true}, StateTimeout},
+		{"deadline", fmt.Errorf("dial: %w", context.DeadlineExceeded), StateTimeout},
+		{"os deadline", &net.OpError{Op: "dial", Err: os.ErrDeadlineExceeded}, StateTimeout},
+		{"refused", &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, StateRefused},
+		{"other", errors.New("network is unreachable"), StateError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Classify(tt.err); got != tt.want {
+				t.Errorf("Classify(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifySSH(t *testing.T) {
+	tests := []struct {
+		output string
+		state  State
+		detail string
+	}{
+		{"Warning: Permanently added 'x' to the list of known hosts.\r\ndeploy@x: Permission denied (publickey).\n", StateAuthFailed, "deploy@x: Permission denied (publickey)."},
+		{"Host key verification failed.\n", StateHostKey, "Host key verification failed."},
+		{"ssh: Could not resolve hostname nope: Name or service not known\n", StateDNSFailure, "ssh: Could not resolve hostname nope: Name or service not known"},
+		{"ssh: connect to host 10.0.0.1 port 22: Connection timed out\n", StateTimeout, "ssh: connect to host 10.0.0.1 port 22: Connection timed out"},
+		{"kex_exchange_identification: read: Connection reset by peer\n", StateError, "kex_exchange_identification: read: Connection reset by peer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.state), func(t *testing.T) {
+			state, detail := ClassifySSH(tt.output)
+			if state != tt.state || detail != tt.detail {
+				t.Errorf("ClassifySSH() = %q, %q, want %q, %q", state, detail, tt.state, tt.detail)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package ssh
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+)
+
+// valueFlags are the ssh options that take a value, as listed in ssh(1)
+const valueFlags = "BbcDEeFIiJLlmOoPpQRSWw"
+
+// SplitArgs splits the arguments given after a server name into ssh options
+// and a remote command. Leading arguments starting with "-" are options,
+// together with the value of any option that takes one; the command starts
+// at the first other argument, or after "--". Like ssh, the command's words
+// are joined with spaces.
+func SplitArgs(args []string) (options []string, command string, err error) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return options, strings.Join(args[i+1:], " "), nil
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			return options, strings.Join(args[i:], " "), nil
+		}
+
+		options = append(options, arg)
+		if flag, needsValue := valueFlag(arg); needsValue {
+			if i+1 == len(args) {
+				return nil, "", fmt.Errorf("ssh option -%c requires a value", flag)
+			}
+			i++
+			options = append(options, args[i])
+		}
+	}
+	return options, "", nil
+}
+
+// valueFlag returns the first option in a cluster such as "-AL" that takes a
+// value, and whether that value is the next argument rather than the rest of arg
+func valueFlag(arg string) (byte, bool) {
+	for j := 1; j < len(arg); j++ {
+		if strings.IndexByte(valueFlags, arg[j]) >= 0 {
+			return arg[j], j == len(arg)-1
+		}
+	}
+	return 0, false
+}
+
+// requestsTTY reports whether options already choose whether to allocate a
+// terminal with -t or -T
+func requestsTTY(options []string) bool {
+	for i := 0; i < len(options); i++ {
+		arg := options[i]
+		if len(arg) < 2 || arg[0] != '-' {
+			continue
+		}
+		for j := 1; j < len(arg); j++ {
+			if arg[j] == 't' || arg[j] == 'T' {
+				return true
+			}
+			if strings.IndexByte(valueFlags, arg[j]) >= 0 {
+				break
+			}
+		}
+		if _, needsValue := valueFlag(arg); needsValue {
+			i++
+		}
+	}
+	return false
+}
+
+// stdinIsTerminal reports whether sshto's input is a terminal. It is a
+// variable so tests can pretend either way.
+var stdinIsTerminal = func() bool {
+	fd := os.Stdin.Fd()
+	return isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd)
+}
@@ -0,0 +1,61 @@
+package ssh
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		options []string
+		command string
+	}{
+		{"command only", []string{"uptime"}, nil, "uptime"},
+		{"options and command", []string{"-A", "-t", "sudo -i"}, []string{"-A", "-t"}, "sudo -i"},
+		{"option values", []string{"-L", "8080:localhost:80", "-oCompression=yes", "ls", "-la"}, []string{"-L", "8080:localhost:80", "-oCompression=yes"}, "ls -la"},
+		{"cluster ending in value flag", []string{"-AL", "8080:localhost:80"}, []string{"-AL", "8080:localhost:80"}, ""},
+		{"double dash", []string{"-A", "--", "-weird-command"}, []string{"-A"}, "-weird-command"},
+		{"empty", nil, nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options, command, err := SplitArgs(tt.args)
+			if err != nil {
+				t.Fatalf("SplitArgs(%q) error = %v", tt.args, err)
+			}
+			if strings.Join(options, " ") != strings.Join(tt.options, " ") {
+				t.Errorf("SplitArgs(%q) options = %q, want %q", tt.args, options, tt.options)
+			}
+			if command != tt.command {
+				t.Errorf("SplitArgs(%q) command = %q, want %q", tt.args, command, tt.command)
+			}
+		})
+	}
+
+	if _, _, err := SplitArgs([]string{"-A", "-L"}); err == nil {
+		t.Error("SplitArgs() with a missing option value expected error, got nil")
+	}
+}
+
+func TestRequestsTTY(t *testing.T) {
+	tests := []struct {
+		options []string
+		want    bool
+	}{
+		{nil, false},
+		{[]string{"-A"}, false},
+		{[]string{"-At"}, true},
+		{[]string{"-T"}, true},
+		{[]string{"-o", "-t"}, false}, // value of -o, not a flag
+		{[]string{"-ot=1"}, false},
+		{[]string{"-v", "-tt"}, true},
+	}
+	for _, tt := range tests {
+		if got := requestsTTY(tt.options); got != tt.want {
+			t.Errorf("requestsTTY(%q) = %v, want %v", tt.options, got, tt.want)
+		}
+	}
+}
@@ -1,44 +1,186 @@
 package ssh
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/codoworks/sshto/internal/config"
 )
 
 // ConnectOptions holds optional overrides for SSH connection
 type ConnectOptions struct {
-	User string
-	Port int
-	Key  string
+	User string `yaml:"user,omitempty"`
+	Port int    `yaml:"port,omitempty"`
+	Key  string `yaml:"key,omitempty"`
+	Jump string `yaml:"jump,omitempty"`
+
+	Forwards   []config.Forward `yaml:"forwards,omitempty"`    // added to the server's own forwards
+	NoForwards bool             `yaml:"no_forwards,omitempty"` // skip the server's configured forwards
+
+	Options map[string]string `yaml:"options,omitempty"` // extra ssh -o options, taking precedence over the config
+
+	// ExtraArgs and Command belong to a single run and are never replayed
+	// by sshto last, which reopens the session
+	ExtraArgs []string `yaml:"-"` // passed to ssh ahead of the configured settings
+	Command   string   `yaml:"-"` // run on the server instead of a login shell
+}
+
+// Merge returns o with the overrides set in other applied on top
+func (o ConnectOptions) Merge(other ConnectOptions) ConnectOptions {
+	if other.User != "" {
+		o.User = other.User
+	}
+	if other.Port != 0 {
+		o.Port = other.Port
+	}
+	if other.Key != "" {
+		o.Key = other.Key
+	}
+	if other.Jump != "" {
+		o.Jump = other.Jump
+	}
+	o.Forwards = append(append([]config.Forward(nil), o.Forwards...), other.Forwards...)
+	if len(o.Forwards) == 0 {
+		o.Forwards = nil
+	}
+	o.NoForwards = o.NoForwards || other.NoForwards
+	o.Options = config.MergeOptions(o.Options, other.Options)
+	o.ExtraArgs = append(append([]string(nil), o.ExtraArgs...), other.ExtraArgs...)
+	if len(o.ExtraArgs) == 0 {
+		o.ExtraArgs = nil
+	}
+	if other.Command != "" {
+		o.Command = other.Command
+	}
+	return o
 }
 
 // Client handles SSH command execution
-type Client struct{}
+type Client struct {
+	Runner    Runner // runs ssh and scp; ExecRunner if nil
+	SSHBinary string // ssh program to run, "ssh" from PATH if empty
+	SCPBinary string // scp program to run, "scp" from PATH if empty
+}
 
 // NewClient creates a new SSH client
 func NewClient() *Client {
 	return &Client{}
 }
 
-// Connect executes an SSH connection to the given server
+// runner returns the Runner to run programs with
+func (c *Client) runner() Runner {
+	if c.Runner == nil {
+		return ExecRunner{}
+	}
+	return c.Runner
+}
+
+// sshBinary returns the ssh program to run
+func (c *Client) sshBinary() string {
+	if c.SSHBinary == "" {
+		return "ssh"
+	}
+	return config.ExpandPath(c.SSHBinary)
+}
+
+// scpBinary returns the scp program to run
+func (c *Client) scpBinary() string {
+	if c.SCPBinary == "" {
+		return "scp"
+	}
+	return config.ExpandPath(c.SCPBinary)
+}
+
+// Connect executes an SSH connection to the given server. When ssh exits
+// with a non-zero status, such as that of a remote command, the error is an
+// *ExitError carrying it.
 func (c *Client) Connect(server *config.Server) error {
-	args := c.buildArgs(server)
+	if native(server) {
+		return c.connectNative(server)
+	}
+	return c.runner().Run(&Command{
+		Name:     c.sshBinary(),
+		Args:     c.buildArgs(server),
+		Stdin:    os.Stdin,
+		Stdout:   os.Stdout,
+		Stderr:   os.Stderr,
+		Attached: true,
+	})
+}
 
-	cmd := exec.Command("ssh", args...)
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+// Run executes command on the server without a terminal, writing its output
+// to stdout and stderr. It never prompts: keys or an agent must be set up.
+func (c *Client) Run(server *config.Server, command string, stdout, stderr io.Writer) error {
+	if native(server) {
+		batch := *server
+		batch.Forwards, batch.Command = nil, command
+		return c.runNative(&batch, 10*time.Second, nil, stdout, stderr, nil)
+	}
+	return c.runner().Run(&Command{
+		Name:   c.sshBinary(),
+		Args:   c.batchArgs(server, 10, command),
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+}
+
+// batchArgs constructs the arguments for running a remote command without
+// prompts. Forwards are left out so that parallel runs do not compete for the
+// same local ports. The batch defaults follow the server's own options
+// because ssh keeps the first value it sees for each option.
+func (c *Client) batchArgs(server *config.Server, connectTimeout int, command string) []string {
+	args := hostArgs(server)
+	args = append(args, "-o", "BatchMode=yes", "-o", "ConnectTimeout="+strconv.Itoa(connectTimeout))
+	args = append(args, c.jumpArgs(server.JumpChain)...)
+	// "--" stops ssh from reading a command that starts with "-" as options
+	return append(args, "--", destination(server), command)
+}
 
-	return cmd.Run()
+// ExitStatus returns the exit code of a finished ssh process. ran is false when
+// err means ssh never ran or did not exit normally (e.g. it could not be started).
+func ExitStatus(err error) (code int, ran bool) {
+	if err == nil {
+		return 0, true
+	}
+	var sshErr *ExitError
+	if errors.As(err, &sshErr) {
+		return sshErr.Code, true
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() >= 0 {
+		return exitErr.ExitCode(), true
+	}
+	return 0, false
 }
 
-// buildArgs constructs the SSH command arguments
+// buildArgs constructs the SSH command arguments. Extra arguments come first
+// so that their -o options win over the configured ones. ssh only allocates a
+// terminal for a login shell, so one is requested for a remote command when
+// sshto runs in a terminal and the extra arguments don't decide either way.
 func (c *Client) buildArgs(server *config.Server) []string {
+	args := append([]string(nil), server.ExtraArgs...)
+	args = append(args, hostArgs(server)...)
+	args = append(args, forwardArgs(server.Forwards)...)
+	args = append(args, c.jumpArgs(server.JumpChain)...)
+
+	if server.Command == "" {
+		return append(args, destination(server))
+	}
+	if !requestsTTY(server.ExtraArgs) && stdinIsTerminal() {
+		args = append(args, "-t")
+	}
+	return append(args, "--", destination(server), server.Command)
+}
+
+// hostArgs returns the identity, port and option flags for a single host
+func hostArgs(server *config.Server) []string {
 	var args []string
 
 	// Add identity file if specified
@@ -52,31 +194,114 @@ func (c *Client) buildArgs(server *config.Server) []string {
 		args = append(args, "-p", strconv.Itoa(server.Port))
 	}
 
-	// Build destination
-	dest := server.Host
-	if server.User != "" {
-		dest = server.User + "@" + server.Host
+	for _, key := range config.SortedOptionKeys(server.Options) {
+		args = append(args, "-o", key+"="+server.Options[key])
 	}
-	args = append(args, dest)
 
 	return args
 }
 
+// forwardArgs returns the -L, -R and -D flags for the given forwards
+func forwardArgs(forwards []config.Forward) []string {
+	var args []string
+	for _, f := range forwards {
+		switch f.Type {
+		case config.ForwardLocal:
+			args = append(args, "-L", f.Spec())
+		case config.ForwardRemote:
+			args = append(args, "-R", f.Spec())
+		case config.ForwardDynamic:
+			args = append(args, "-D", f.Spec())
+		}
+	}
+	return args
+}
+
+// destination returns the [user@]host target for a server
+func destination(server *config.Server) string {
+	if server.User != "" {
+		return server.User + "@" + server.Host
+	}
+	return server.Host
+}
+
+// jumpArgs returns the flags that route a connection through the given hops.
+// ProxyJump can't carry per-hop identity files or options, so when any hop
+// needs them the chain is expressed as nested ProxyCommands instead.
+func (c *Client) jumpArgs(hops []config.Server) []string {
+	if len(hops) == 0 {
+		return nil
+	}
+
+	for i := range hops {
+		if hops[i].Key != "" || len(hops[i].Options) > 0 {
+			return []string{"-o", "ProxyCommand=" + c.proxyCommand(hops)}
+		}
+	}
+
+	jumps := make([]string, len(hops))
+	for i := range hops {
+		jumps[i] = jumpHost(&hops[i])
+	}
+	return []string{"-J", strings.Join(jumps, ",")}
+}
+
+// jumpHost formats a hop in ProxyJump's [user@]host[:port] syntax
+func jumpHost(hop *config.Server) string {
+	host := hop.Host
+	if hop.Port != 0 && hop.Port != 22 {
+		if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+		host = fmt.Sprintf("%s:%d", host, hop.Port)
+	}
+	if hop.User != "" {
+		host = hop.User + "@" + host
+	}
+	return host
+}
+
+// proxyCommand builds an ssh -W command that reaches %h:%p through hops.
+// Earlier hops become a ProxyCommand of the last one; their % tokens are
+// escaped because ssh expands the outer command before running it.
+func (c *Client) proxyCommand(hops []config.Server) string {
+	last := &hops[len(hops)-1]
+
+	args := append([]string{c.sshBinary()}, hostArgs(last)...)
+	if len(hops) > 1 {
+		inner := c.proxyCommand(hops[:len(hops)-1])
+		args = append(args, "-o", "ProxyCommand="+strings.ReplaceAll(inner, "%", "%%"))
+	}
+	args = append(args, "-W", "%h:%p", destination(last))
+
+	return shellJoin(args)
+}
+
 // BuildCommand returns the SSH command string for display
 func (c *Client) BuildCommand(server *config.Server) string {
 	args := c.buildArgs(server)
-	return "ssh " + strings.Join(args, " ")
+	return shellJoin(append([]string{c.sshBinary()}, args...))
+}
+
+// BuildRunCommand returns the ssh command Run executes for command, for display
+func (c *Client) BuildRunCommand(server *config.Server, command string) string {
+	return shellJoin(append([]string{c.sshBinary()}, c.batchArgs(server, 10, command)...))
 }
 
 // TestConnection tests if an SSH connection can be established
 func (c *Client) TestConnection(server *config.Server) error {
-	args := c.buildArgs(server)
-	args = append(args, "-o", "ConnectTimeout=5", "-o", "BatchMode=yes", "exit")
-
-	cmd := exec.Command("ssh", args...)
-	output, err := cmd.CombinedOutput()
+	if native(server) {
+		return testNative(server, 5*time.Second)
+	}
+	var output bytes.Buffer
+	err := c.runner().Run(&Command{
+		Name:   c.sshBinary(),
+		Args:   c.batchArgs(server, 5, "exit"),
+		Stdout: &output,
+		Stderr: &output,
+	})
 	if err != nil {
-		return fmt.Errorf("connection test failed: %s", string(output))
+		return fmt.Errorf("connection test failed: %s", output.String())
 	}
 	return nil
 }
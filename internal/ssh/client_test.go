@@ -2,6 +2,8 @@ package ssh
 
 import (
 	"os"
+	"os/exec"
+	"runtime"
 	"strings"
 	"testing"
 
@@ -179,3 +181,240 @@ func TestBuildArgsPortZero(t *testing.T) {
 		}
 	}
 }
+
+func TestBuildArgsJumpHosts(t *testing.T) {
+	client := NewClient()
+
+	tests := []struct {
+		name     string
+		server   *config.Server
+		expected []string
+	}{
+		{
+			"single hop",
+			&config.Server{Host: "10.0.0.5", User: "app", JumpChain: []config.Server{
+				{Host: "bastion.example.com", User: "admin"},
+			}},
+			[]string{"-J", "admin@bastion.example.com", "app@10.0.0.5"},
+		},
+		{
+			"chained hops with ports",
+			&config.Server{Host: "10.0.0.5", JumpChain: []config.Server{
+				{Host: "outer.example.com", Port: 2222},
+				{Host: "2001:db8::1", User: "ops", Port: 2200},
+				{Host: "inner.example.com", Port: 22},
+			}},
+			[]string{"-J", "outer.example.com:2222,ops@[2001:db8::1]:2200,inner.example.com", "10.0.0.5"},
+		},
+		{
+			"hop with key uses ProxyCommand",
+			&config.Server{Host: "10.0.0.5", Port: 2222, JumpChain: []config.Server{
+				{Host: "bastion.example.com", User: "admin", Key: "/keys/bastion"},
+			}},
+			[]string{"-p", "2222", "-o", "ProxyCommand=ssh -i /keys/bastion -W %h:%p admin@bastion.example.com", "10.0.0.5"},
+		},
+		{
+			"chained hops with keys nest ProxyCommands",
+			&config.Server{Host: "10.0.0.5", JumpChain: []config.Server{
+				{Host: "outer.example.com", Key: "/keys/outer"},
+				{Host: "inner.example.com", Port: 2222},
+			}},
+			[]string{"-o", "ProxyCommand=ssh -p 2222 -o 'ProxyCommand=ssh -i /keys/outer -W %%h:%%p outer.example.com' -W %h:%p inner.example.com", "10.0.0.5"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := client.buildArgs(tt.server)
+			if len(args) != len(tt.expected) {
+				t.Fatalf("buildArgs() = %q, want %q", args, tt.expected)
+			}
+			for i := range args {
+				if args[i] != tt.expected[i] {
+					t.Errorf("buildArgs()[%d] = %q, want %q", i, args[i], tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestBuildCommandQuotesArgs(t *testing.T) {
+	client := NewClient()
+	server := &config.Server{Host: "10.0.0.5", JumpChain: []config.Server{
+		{Host: "bastion.example.com", Key: "/keys/my key"},
+	}}
+
+	expected := `ssh -o 'ProxyCommand=ssh -i '\''/keys/my key'\'' -W %h:%p bastion.example.com' 10.0.0.5`
+	if cmd := client.BuildCommand(server); cmd != expected {
+		t.Errorf("BuildCommand() = %q, want %q", cmd, expected)
+	}
+}
+
+func TestBuildArgsForwards(t *testing.T) {
+	client := NewClient()
+	server := &config.Server{
+		Host: "db.example.com",
+		Port: 2222,
+		Forwards: []config.Forward{
+			{Type: config.ForwardLocal, Listen: "5432", Target: "localhost:5432"},
+			{Type: config.ForwardRemote, Listen: "9000", Target: "localhost:9000"},
+			{Type: config.ForwardDynamic, Listen: "1080"},
+		},
+		JumpChain: []config.Server{{Host: "bastion.example.com"}},
+	}
+
+	expected := []string{
+		"-p", "2222",
+		"-L", "5432:localhost:5432",
+		"-R", "9000:localhost:9000",
+		"-D", "1080",
+		"-J", "bastion.example.com",
+		"db.example.com",
+	}
+
+	args := client.buildArgs(server)
+	if strings.Join(args, " ") != strings.Join(expected, " ") {
+		t.Errorf("buildArgs() = %q, want %q", args, expected)
+	}
+}
+
+func TestBuildArgsOptions(t *testing.T) {
+	client := NewClient()
+
+	server := &config.Server{
+		Host:    "example.com",
+		Key:     "/tmp/key",
+		Options: map[string]string{"StrictHostKeyChecking": "no", "ForwardAgent": "yes"},
+	}
+	expected := "-i /tmp/key -o ForwardAgent=yes -o StrictHostKeyChecking=no example.com"
+	if got := strings.Join(client.buildArgs(server), " "); got != expected {
+		t.Errorf("buildArgs() = %q, want %q", got, expected)
+	}
+
+	// Hop options can't be expressed with -J
+	server = &config.Server{Host: "example.com", JumpChain: []config.Server{
+		{Host: "bastion", Options: map[string]string{"ServerAliveInterval": "30"}},
+	}}
+	expected = "-o ProxyCommand=ssh -o ServerAliveInterval=30 -W %h:%p bastion example.com"
+	if got := strings.Join(client.buildArgs(server), " "); got != expected {
+		t.Errorf("buildArgs() = %q, want %q", got, expected)
+	}
+}
+
+func TestBuildArgsCommand(t *testing.T) {
+	client := NewClient()
+	defer func(f func() bool) { stdinIsTerminal = f }(stdinIsTerminal)
+
+	tests := []struct {
+		name     string
+		terminal bool
+		extra    []string
+		expected string
+	}{
+		{"terminal", true, nil, "-p 2222 -t -- deploy@10.0.0.1 sudo -i"},
+		{"no terminal", false, nil, "-p 2222 -- deploy@10.0.0.1 sudo -i"},
+		{"explicit -T", true, []string{"-A", "-T"}, "-A -T -p 2222 -- deploy@10.0.0.1 sudo -i"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdinIsTerminal = func() bool { return tt.terminal }
+			server := &config.Server{
+				Host:      "10.0.0.1",
+				User:      "deploy",
+				Port:      2222,
+				ExtraArgs: tt.extra,
+				Command:   "sudo -i",
+			}
+			if got := strings.Join(client.buildArgs(server), " "); got != tt.expected {
+				t.Errorf("buildArgs() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+
+	// Extra arguments without a command go ahead of the configured ones
+	stdinIsTerminal = func() bool { return true }
+	server := &config.Server{Host: "10.0.0.1", ExtraArgs: []string{"-o", "Compression=no"}, Options: map[string]string{"Compression": "yes"}}
+	expected := "-o Compression=no -o Compression=yes 10.0.0.1"
+	if got := strings.Join(client.buildArgs(server), " "); got != expected {
+		t.Errorf("buildArgs() = %q, want %q", got, expected)
+	}
+}
+
+func TestExitStatus(t *testing.T) {
+	if code, ran := ExitStatus(nil); code != 0 || !ran {
+		t.Errorf("ExitStatus(nil) = %d, %v, want 0, true", code, ran)
+	}
+
+	err := exec.Command("/nonexistent/binary").Run()
+	if _, ran := ExitStatus(err); ran {
+		t.Error("ExitStatus() should report a command that failed to start as not run")
+	}
+
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	err = exec.Command("sh", "-c", "exit 3").Run()
+	if code, ran := ExitStatus(err); code != 3 || !ran {
+		t.Errorf("ExitStatus(exit 3) = %d, %v, want 3, true", code, ran)
+	}
+}
+
+func TestConnectOptionsMerge(t *testing.T) {
+	base := ConnectOptions{
+		User:     "deploy",
+		Port:     2222,
+		Forwards: []config.Forward{{Type: config.ForwardDynamic, Listen: "1080"}},
+		Options:  map[string]string{"Compression": "yes"},
+	}
+	merged := base.Merge(ConnectOptions{
+		User:       "root",
+		NoForwards: true,
+		Forwards:   []config.Forward{{Type: config.ForwardDynamic, Listen: "1081"}},
+		Options:    map[string]string{"compression": "no"},
+	})
+
+	if merged.User != "root" || merged.Port != 2222 || !merged.NoForwards {
+		t.Errorf("Merge() = %+v", merged)
+	}
+	if len(merged.Forwards) != 2 || merged.Forwards[1].Listen != "1081" {
+		t.Errorf("Merge().Forwards = %+v, want both forwards", merged.Forwards)
+	}
+	if len(merged.Options) != 1 || merged.Options["compression"] != "no" {
+		t.Errorf("Merge().Options = %v, want compression=no", merged.Options)
+	}
+	if len(base.Forwards) != 1 {
+		t.Error("Merge() should not modify the receiver")
+	}
+
+	withCommand := base.Merge(ConnectOptions{ExtraArgs: []string{"-A"}, Command: "uptime"})
+	if len(withCommand.ExtraArgs) != 1 || withCommand.Command != "uptime" {
+		t.Errorf("Merge() = %+v, want extra args and command", withCommand)
+	}
+
+	if empty := (ConnectOptions{}).Merge(ConnectOptions{}); empty.Forwards != nil || empty.Options != nil || empty.ExtraArgs != nil {
+		t.Errorf("Merge() of empty options = %+v, want zero value", empty)
+	}
+}
+
+func TestBatchArgs(t *testing.T) {
+	client := NewClient()
+	server := &config.Server{
+		Host:      "10.0.0.1",
+		User:      "deploy",
+		Port:      2222,
+		Options:   map[string]string{"ConnectTimeout": "30"},
+		Forwards:  []config.Forward{{Type: config.ForwardDynamic, Listen: "1080"}},
+		JumpChain: []config.Server{{Host: "bastion.example.com"}},
+	}
+
+	got := client.batchArgs(server, 10, "systemctl status nginx")
+	want := []string{
+		"-p", "2222", "-o", "ConnectTimeout=30",
+		"-o", "BatchMode=yes", "-o", "ConnectTimeout=10",
+		"-J", "bastion.example.com",
+		"--", "deploy@10.0.0.1", "systemctl status nginx",
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("batchArgs() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,101 @@
+package ssh
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/codoworks/sshto/internal/config"
+)
+
+// Location is one side of a copy: a local path, or a path on the server
+type Location struct {
+	Path   string
+	Remote bool
+}
+
+// CopyOptions controls a file transfer
+type CopyOptions struct {
+	Rsync     bool     // use rsync instead of scp
+	Recursive bool     // copy directories; rsync always does
+	Args      []string // extra arguments passed to scp or rsync
+}
+
+// CopyCommand returns the scp or rsync command, including the program name,
+// that copies sources to dest with the server's connection settings
+func (c *Client) CopyCommand(server *config.Server, sources []Location, dest Location, opts CopyOptions) []string {
+	var args []string
+	if opts.Rsync {
+		// rsync runs ssh itself, so the ssh flags travel as a single -e command
+		ssh := append([]string{c.sshBinary()}, hostArgs(server)...)
+		ssh = append(ssh, c.jumpArgs(server.JumpChain)...)
+		args = []string{"rsync", "-az", "-e", shellJoin(ssh)}
+	} else {
+		args = []string{c.scpBinary()}
+		if opts.Recursive {
+			args = append(args, "-r")
+		}
+		args = append(args, scpHostArgs(server)...)
+		args = append(args, c.jumpArgs(server.JumpChain)...)
+	}
+	args = append(args, opts.Args...)
+
+	for _, src := range sources {
+		args = append(args, remotePath(server, src))
+	}
+	return append(args, remotePath(server, dest))
+}
+
+// BuildCopyCommand returns the command Copy runs, for display
+func (c *Client) BuildCopyCommand(server *config.Server, sources []Location, dest Location, opts CopyOptions) string {
+	return shellJoin(c.CopyCommand(server, sources, dest, opts))
+}
+
+// Copy transfers files between the local machine and the server
+func (c *Client) Copy(server *config.Server, sources []Location, dest Location, opts CopyOptions) error {
+	if native(server) {
+		return fmt.Errorf("copying needs the %s transport and scp or rsync", config.TransportOpenSSH)
+	}
+	args := c.CopyCommand(server, sources, dest, opts)
+
+	return c.runner().Run(&Command{
+		Name:     args[0],
+		Args:     args[1:],
+		Stdin:    os.Stdin,
+		Stdout:   os.Stdout,
+		Stderr:   os.Stderr,
+		Attached: true,
+	})
+}
+
+// scpHostArgs is hostArgs for scp, which takes the port as -P
+func scpHostArgs(server *config.Server) []string {
+	var args []string
+	if server.Key != "" {
+		args = append(args, "-i", config.ExpandPath(server.Key))
+	}
+	if server.Port != 0 && server.Port != 22 {
+		args = append(args, "-P", strconv.Itoa(server.Port))
+	}
+	for _, key := range config.SortedOptionKeys(server.Options) {
+		args = append(args, "-o", key+"="+server.Options[key])
+	}
+	return args
+}
+
+// remotePath returns the argument for a location: the path itself when it is
+// local, or [user@]host:path when it is on the server
+func remotePath(server *config.Server, loc Location) string {
+	if !loc.Remote {
+		return loc.Path
+	}
+	host := server.Host
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if server.User != "" {
+		host = server.User + "@" + host
+	}
+	return host + ":" + loc.Path
+}
@@ -0,0 +1,86 @@
+package ssh
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/codoworks/sshto/internal/config"
+)
+
+func TestCopyCommand(t *testing.T) {
+	client := NewClient()
+	home, _ := os.UserHomeDir()
+	key := filepath.Join(home, ".ssh", "deploy")
+
+	server := &config.Server{
+		Host:      "10.0.0.1",
+		User:      "deploy",
+		Port:      2222,
+		Key:       "~/.ssh/deploy",
+		Options:   map[string]string{"Compression": "yes"},
+		Forwards:  []config.Forward{{Type: config.ForwardDynamic, Listen: "1080"}},
+		JumpChain: []config.Server{{Host: "bastion.example.com"}},
+	}
+	upload := []Location{{Path: "app.tar.gz"}, {Path: "conf dir"}}
+	remote := Location{Path: "/srv/app/", Remote: true}
+
+	tests := []struct {
+		name    string
+		sources []Location
+		dest    Location
+		opts    CopyOptions
+		want    []string
+	}{
+		{
+			"scp upload",
+			upload, remote,
+			CopyOptions{Recursive: true},
+			[]string{"scp", "-r", "-i", key, "-P", "2222", "-o", "Compression=yes", "-J", "bastion.example.com",
+				"app.tar.gz", "conf dir", "deploy@10.0.0.1:/srv/app/"},
+		},
+		{
+			"scp download with extra args",
+			[]Location{{Path: "/var/log/syslog", Remote: true}}, Location{Path: "."},
+			CopyOptions{Args: []string{"-C"}},
+			[]string{"scp", "-i", key, "-P", "2222", "-o", "Compression=yes", "-J", "bastion.example.com",
+				"-C", "deploy@10.0.0.1:/var/log/syslog", "."},
+		},
+		{
+			"rsync upload",
+			upload, remote,
+			CopyOptions{Rsync: true, Args: []string{"--delete"}},
+			[]string{"rsync", "-az", "-e", "ssh -i " + shellQuote(key) + " -p 2222 -o Compression=yes -J bastion.example.com",
+				"--delete", "app.tar.gz", "conf dir", "deploy@10.0.0.1:/srv/app/"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := client.CopyCommand(server, tt.sources, tt.dest, tt.opts)
+			if strings.Join(got, "\x00") != strings.Join(tt.want, "\x00") {
+				t.Errorf("CopyCommand() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemotePath(t *testing.T) {
+	tests := []struct {
+		server config.Server
+		loc    Location
+		want   string
+	}{
+		{config.Server{Host: "example.com"}, Location{Path: "local.txt"}, "local.txt"},
+		{config.Server{Host: "example.com"}, Location{Path: "", Remote: true}, "example.com:"},
+		{config.Server{Host: "example.com", User: "root"}, Location{Path: "/etc/hosts", Remote: true}, "root@example.com:/etc/hosts"},
+		{config.Server{Host: "::1", User: "root"}, Location{Path: "f", Remote: true}, "root@[::1]:f"},
+	}
+
+	for _, tt := range tests {
+		if got := remotePath(&tt.server, tt.loc); got != tt.want {
+			t.Errorf("remotePath(%q, %+v) = %q, want %q", tt.server.Host, tt.loc, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package ssh
+
+import "syscall"
+
+// detachedProcAttr starts a process in its own session, so it survives the
+// terminal closing and does not receive the terminal's signals
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
@@ -0,0 +1,14 @@
+//go:build windows
+
+package ssh
+
+import "syscall"
+
+// detachedProcess is the DETACHED_PROCESS creation flag, missing from syscall
+const detachedProcess = 0x00000008
+
+// detachedProcAttr starts a process without a console in a new process group,
+// so it survives the console closing and does not receive Ctrl+C
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP | detachedProcess}
+}
@@ -0,0 +1,311 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	gossh "golang.org/x/crypto/ssh"
+	"golang.org/x/term"
+
+	"github.com/codoworks/sshto/internal/config"
+)
+
+// The native transport talks to servers with golang.org/x/crypto/ssh instead
+// of running the ssh binary, for systems without OpenSSH. It supports key
+// and agent authentication, known_hosts verification, jump hosts, terminals
+// and remote commands, but not port forwards, ssh options beyond
+// ConnectTimeout, StrictHostKeyChecking and UserKnownHostsFile, or extra ssh
+// arguments.
+
+// size is a terminal size in columns and rows
+type size struct {
+	width, height int
+}
+
+// terminal describes the local terminal of an interactive native session
+type terminal struct {
+	term   string
+	size   size
+	resize <-chan size
+}
+
+// nativeSignals maps the signals relayed to a running session to their ssh names
+var nativeSignals = map[os.Signal]gossh.Signal{
+	syscall.SIGINT:  gossh.SIGINT,
+	syscall.SIGTERM: gossh.SIGTERM,
+	syscall.SIGHUP:  gossh.SIGHUP,
+}
+
+// native reports whether the server uses the native transport
+func native(server *config.Server) bool {
+	return server.Transport == config.TransportNative
+}
+
+// checkNative reports settings the native transport cannot honour
+func checkNative(server *config.Server) error {
+	if len(server.Forwards) > 0 {
+		return fmt.Errorf("port forwards need the %s transport (use --no-forwards)", config.TransportOpenSSH)
+	}
+	if len(server.ExtraArgs) > 0 {
+		return fmt.Errorf("ssh arguments need the %s transport", config.TransportOpenSSH)
+	}
+	return nil
+}
+
+// connectNative runs an interactive shell, or the server's command, with the
+// native transport. Like ssh, a terminal is allocated when sshto runs in one,
+// and connection errors are printed and reported as exit status 255.
+func (c *Client) connectNative(server *config.Server) error {
+	if err := checkNative(server); err != nil {
+		return err
+	}
+
+	var tty *terminal
+	if stdinIsTerminal() {
+		fd := int(os.Stdin.Fd())
+		width, height, err := term.GetSize(fd)
+		if err != nil {
+			width, height = 80, 24
+		}
+		resize, stop := watchResize(fd)
+		defer stop()
+
+		tty = &terminal{term: os.Getenv("TERM"), size: size{width, height}, resize: resize}
+		if tty.term == "" {
+			tty.term = "xterm-256color"
+		}
+	}
+
+	return c.runNative(server, 0, os.Stdin, os.Stdout, os.Stderr, tty)
+}
+
+// runNative runs a session and waits for it to end. A terminal given as tty
+// is put in raw mode while the session runs. Sessions without stdin are
+// batch runs, which never prompt for a key passphrase.
+func (c *Client) runNative(server *config.Server, timeout time.Duration, stdin io.Reader, stdout, stderr io.Writer, tty *terminal) error {
+	client, err := dialNative(server, timeout, stdin != nil)
+	if err != nil {
+		fmt.Fprintf(stderr, "ssh: %v\n", err)
+		return &ExitError{Program: "ssh", Code: 255}
+	}
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		return fmt.Errorf("opening session: %w", err)
+	}
+	defer session.Close()
+
+	session.Stdout = stdout
+	session.Stderr = stderr
+	if stdin != nil {
+		// Session.Stdin would make Wait block until the next read from stdin returns
+		in, err := session.StdinPipe()
+		if err != nil {
+			return fmt.Errorf("opening session: %w", err)
+		}
+		go func() {
+			_, _ = io.Copy(in, stdin)
+			in.Close()
+		}()
+	}
+
+	if tty != nil {
+		modes := gossh.TerminalModes{gossh.ECHO: 1, gossh.TTY_OP_ISPEED: 14400, gossh.TTY_OP_OSPEED: 14400}
+		if err := session.RequestPty(tty.term, tty.size.height, tty.size.width, modes); err != nil {
+			return fmt.Errorf("requesting terminal: %w", err)
+		}
+		if f, ok := stdin.(*os.File); ok {
+			state, err := term.MakeRaw(int(f.Fd()))
+			if err == nil {
+				defer term.Restore(int(f.Fd()), state)
+			}
+		}
+		go func() {
+			for s := range tty.resize {
+				_ = session.WindowChange(s.height, s.width)
+			}
+		}()
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, forwardedSignals...)
+	defer func() {
+		signal.Stop(sigs)
+		close(sigs)
+	}()
+	go func() {
+		for sig := range sigs {
+			if name, ok := nativeSignals[sig]; ok {
+				_ = session.Signal(name)
+			}
+		}
+	}()
+
+	if server.Command == "" {
+		err = session.Shell()
+	} else {
+		err = session.Start(server.Command)
+	}
+	if err != nil {
+		return fmt.Errorf("starting session: %w", err)
+	}
+	return nativeExitError(session.Wait())
+}
+
+// nativeExitError converts the end of a session to an *ExitError like the
+// one for the ssh binary, which exits with 255 when it loses the connection
+func nativeExitError(err error) error {
+	var exitErr *gossh.ExitError
+	var missing *gossh.ExitMissingError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &exitErr):
+		return &ExitError{Program: "ssh", Code: exitErr.ExitStatus()}
+	case errors.As(err, &missing):
+		return &ExitError{Program: "ssh", Code: 255}
+	}
+	return err
+}
+
+// testNative logs in to the server with the native transport and disconnects
+func testNative(server *config.Server, timeout time.Duration) error {
+	client, err := dialNative(server, timeout, false)
+	if err != nil {
+		return fmt.Errorf("connection test failed: %v", err)
+	}
+	return client.Close()
+}
+
+// nativeClient is a connection to a server, possibly through jump hosts
+type nativeClient struct {
+	*gossh.Client
+	hops []*gossh.Client // connections to the jump hosts, first hop first
+}
+
+// Close closes the connection and those to the jump hosts
+func (c *nativeClient) Close() error {
+	var err error
+	if c.Client != nil {
+		err = c.Client.Close()
+	}
+	for i := len(c.hops) - 1; i >= 0; i-- {
+		c.hops[i].Close()
+	}
+	return err
+}
+
+// dialNative connects and logs in to the server through its jump hosts.
+// The server's ConnectTimeout option, in seconds, overrides timeout, and
+// a zero timeout waits as long as the operating system does. prompt allows
+// asking for the passphrase of an encrypted key.
+func dialNative(server *config.Server, timeout time.Duration, prompt bool) (*nativeClient, error) {
+	if value, ok := config.LookupOption(server.Options, "ConnectTimeout"); ok {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+			timeout = time.Duration(seconds) * time.Second
+		}
+	}
+
+	chain := append(append([]config.Server(nil), server.JumpChain...), *server)
+	nc := &nativeClient{}
+	var through *gossh.Client
+	for i := range chain {
+		client, err := dialHop(&chain[i], through, timeout, prompt)
+		if err != nil {
+			nc.Close()
+			return nil, err
+		}
+		if i < len(chain)-1 {
+			nc.hops = append(nc.hops, client)
+		} else {
+			nc.Client = client
+		}
+		through = client
+	}
+	return nc, nil
+}
+
+// dialHop connects and logs in to a single host, directly or through the
+// previous hop's connection
+func dialHop(host *config.Server, through *gossh.Client, timeout time.Duration, prompt bool) (*gossh.Client, error) {
+	port := host.Port
+	if port == 0 {
+		port = 22
+	}
+	address := net.JoinHostPort(host.Host, strconv.Itoa(port))
+
+	auth, done, err := nativeAuth(host, prompt)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	hostKey, algos, err := hostKeyCallback(host, address)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &gossh.ClientConfig{
+		User:              nativeUser(host),
+		Auth:              auth,
+		HostKeyCallback:   hostKey,
+		HostKeyAlgorithms: algos,
+		Timeout:           timeout,
+	}
+
+	var conn net.Conn
+	if through == nil {
+		conn, err = net.DialTimeout("tcp", address, timeout)
+	} else {
+		conn, err = through.Dial("tcp", address)
+	}
+	if err != nil {
+		return nil, dialError(host, port, err)
+	}
+
+	if timeout > 0 {
+		_ = conn.SetDeadline(time.Now().Add(timeout))
+	}
+	c, chans, reqs, err := gossh.NewClientConn(conn, address, cfg)
+	if err != nil {
+		conn.Close()
+		return nil, handshakeError(host, err)
+	}
+	_ = conn.SetDeadline(time.Time{})
+	return gossh.NewClient(c, chans, reqs), nil
+}
+
+// dialError words a failed connection like ssh does
+func dialError(host *config.Server, port int, err error) error {
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &dnsErr):
+		return fmt.Errorf("Could not resolve hostname %s: %v", host.Host, dnsErr.Err)
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return fmt.Errorf("connect to host %s port %d: Connection timed out", host.Host, port)
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return fmt.Errorf("connect to host %s port %d: Connection refused", host.Host, port)
+	}
+	return fmt.Errorf("connect to host %s port %d: %v", host.Host, port, err)
+}
+
+// handshakeError words a failed login like ssh does
+func handshakeError(host *config.Server, err error) error {
+	var netErr net.Error
+	switch {
+	case errors.As(err, &netErr) && netErr.Timeout(), strings.Contains(err.Error(), "i/o timeout"):
+		return fmt.Errorf("connection to %s timed out during login", host.Host)
+	case strings.Contains(err.Error(), "unable to authenticate"):
+		return fmt.Errorf("%s@%s: Permission denied (publickey)", nativeUser(host), host.Host)
+	}
+	return fmt.Errorf("%s: %v", host.Host, err)
+}
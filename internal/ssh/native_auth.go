@@ -0,0 +1,208 @@
+package ssh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+
+	gossh "golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"golang.org/x/term"
+
+	"github.com/codoworks/sshto/internal/config"
+)
+
+// defaultIdentities are the key files tried when a server has no key,
+// in the order ssh tries them
+var defaultIdentities = []string{"~/.ssh/id_ed25519", "~/.ssh/id_ecdsa", "~/.ssh/id_rsa"}
+
+// nativeUser returns the login name for a server, defaulting to the local user like ssh
+func nativeUser(server *config.Server) string {
+	if server.User != "" {
+		return server.User
+	}
+	u, err := user.Current()
+	if err != nil {
+		return ""
+	}
+	// Windows reports DOMAIN\name
+	if i := strings.LastIndex(u.Username, `\`); i >= 0 {
+		return u.Username[i+1:]
+	}
+	return u.Username
+}
+
+// nativeAuth returns public key authentication using the server's key, or
+// the default keys when it has none, followed by the keys in the ssh agent.
+// The returned function releases the agent connection once logged in.
+func nativeAuth(server *config.Server, prompt bool) ([]gossh.AuthMethod, func(), error) {
+	var signers []gossh.Signer
+	if server.Key != "" {
+		signer, err := loadKey(config.ExpandPath(server.Key), prompt)
+		if err != nil {
+			return nil, nil, err
+		}
+		signers = append(signers, signer)
+	} else {
+		for _, path := range defaultIdentities {
+			// Keys that are missing or need a passphrase are left to the agent
+			if signer, err := loadKey(config.ExpandPath(path), false); err == nil {
+				signers = append(signers, signer)
+			}
+		}
+	}
+
+	done := func() {}
+	if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+		if conn, err := net.Dial("unix", sock); err == nil {
+			if agentSigners, err := agent.NewClient(conn).Signers(); err == nil {
+				signers = append(signers, agentSigners...)
+			}
+			done = func() { conn.Close() }
+		}
+	}
+
+	return []gossh.AuthMethod{gossh.PublicKeys(signers...)}, done, nil
+}
+
+// loadKey reads a private key file. Encrypted keys are unlocked with a
+// passphrase read from the terminal when prompt is set and there is one.
+func loadKey(path string, prompt bool) (gossh.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading key: %w", err)
+	}
+
+	signer, err := gossh.ParsePrivateKey(data)
+	var missing *gossh.PassphraseMissingError
+	if !errors.As(err, &missing) {
+		if err != nil {
+			return nil, fmt.Errorf("parsing key %s: %w", path, err)
+		}
+		return signer, nil
+	}
+
+	if !prompt || !stdinIsTerminal() {
+		return nil, fmt.Errorf("key %s needs a passphrase: add it to ssh-agent", path)
+	}
+	fmt.Fprintf(os.Stderr, "Enter passphrase for key '%s': ", path)
+	passphrase, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, fmt.Errorf("reading passphrase: %w", err)
+	}
+	signer, err = gossh.ParsePrivateKeyWithPassphrase(data, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("parsing key %s: %w", path, err)
+	}
+	return signer, nil
+}
+
+// knownHostsFiles returns the known_hosts files for a server: those named by
+// its UserKnownHostsFile option, or ~/.ssh/known_hosts
+func knownHostsFiles(server *config.Server) []string {
+	files := []string{"~/.ssh/known_hosts"}
+	if value, ok := config.LookupOption(server.Options, "UserKnownHostsFile"); ok {
+		files = strings.Fields(value)
+	}
+	for i := range files {
+		files[i] = config.ExpandPath(files[i])
+	}
+	return files
+}
+
+// hostKeyCallback verifies host keys against the server's known_hosts files
+// the way ssh does, honouring StrictHostKeyChecking: unknown hosts are
+// refused unless it is accept-new or no, which add them to the first file.
+// Changed host keys are always refused. It also returns the key algorithms
+// known for the host, so the server is asked for a key that can be checked.
+func hostKeyCallback(server *config.Server, address string) (gossh.HostKeyCallback, []string, error) {
+	files := knownHostsFiles(server)
+	var existing []string
+	for _, f := range files {
+		if _, err := os.Stat(f); err == nil {
+			existing = append(existing, f)
+		}
+	}
+	known, err := knownhosts.New(existing...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	strict, _ := config.LookupOption(server.Options, "StrictHostKeyChecking")
+	strict = strings.ToLower(strict)
+
+	callback := func(hostname string, remote net.Addr, key gossh.PublicKey) error {
+		err := known(hostname, remote, key)
+		var keyErr *knownhosts.KeyError
+		if !errors.As(err, &keyErr) {
+			return err
+		}
+		// Worded like ssh's errors so status checks classify them the same way
+		if len(keyErr.Want) > 0 {
+			return fmt.Errorf("REMOTE HOST IDENTIFICATION HAS CHANGED: the %s host key for %s does not match %s:%d",
+				key.Type(), hostname, keyErr.Want[0].Filename, keyErr.Want[0].Line)
+		}
+		if strict == "accept-new" || strict == "no" || strict == "off" {
+			return addKnownHost(files[0], hostname, key)
+		}
+		return fmt.Errorf("Host key verification failed: no host key is known for %s (set StrictHostKeyChecking=accept-new to trust it on first use)", hostname)
+	}
+
+	return callback, knownAlgorithms(known, address), nil
+}
+
+// knownAlgorithms returns the host key algorithms known_hosts has keys of
+// for address. Without them the server may offer a key of another type,
+// which would look like a changed key.
+func knownAlgorithms(known gossh.HostKeyCallback, address string) []string {
+	// Checking a key no host has reveals the keys the host does have
+	_, probe, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil
+	}
+	signer, err := gossh.NewSignerFromKey(probe)
+	if err != nil {
+		return nil
+	}
+
+	var keyErr *knownhosts.KeyError
+	if !errors.As(known(address, &net.TCPAddr{IP: net.IPv4zero}, signer.PublicKey()), &keyErr) {
+		return nil
+	}
+
+	var algos []string
+	for _, k := range keyErr.Want {
+		if k.Key.Type() == gossh.KeyAlgoRSA {
+			algos = append(algos, gossh.KeyAlgoRSASHA512, gossh.KeyAlgoRSASHA256)
+		}
+		algos = append(algos, k.Key.Type())
+	}
+	return algos
+}
+
+// addKnownHost appends a host key to a known_hosts file
+func addKnownHost(path, hostname string, key gossh.PublicKey) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("creating known_hosts directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("opening known_hosts: %w", err)
+	}
+	defer f.Close()
+
+	line := knownhosts.Line([]string{knownhosts.Normalize(hostname)}, key)
+	if _, err := fmt.Fprintln(f, line); err != nil {
+		return fmt.Errorf("writing known_hosts: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Warning: Permanently added '%s' (%s) to the list of known hosts.\n", knownhosts.Normalize(hostname), key.Type())
+	return nil
+}
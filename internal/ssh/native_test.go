@@ -0,0 +1,459 @@
+package ssh
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	gossh "golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/codoworks/sshto/internal/config"
+)
+
+// testServer is an in-process SSH server. Commands print "ran <command>",
+// with "exit N" exiting with status N. A shell exits once its window has
+// been resized. direct-tcpip channels make it usable as a jump host.
+type testServer struct {
+	addr    string
+	port    int
+	hostKey gossh.Signer
+
+	mu      sync.Mutex
+	ptys    []string // "term WxH" for each terminal request
+	resizes []string // "WxH" for each window change
+}
+
+func newTestServer(t *testing.T, authorized gossh.PublicKey) *testServer {
+	t.Helper()
+	s := &testServer{hostKey: newTestSigner(t)}
+
+	cfg := &gossh.ServerConfig{
+		PublicKeyCallback: func(_ gossh.ConnMetadata, key gossh.PublicKey) (*gossh.Permissions, error) {
+			if bytes.Equal(key.Marshal(), authorized.Marshal()) {
+				return nil, nil
+			}
+			return nil, errors.New("unknown key")
+		},
+	}
+	cfg.AddHostKey(s.hostKey)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	s.addr = ln.Addr().String()
+	s.port = ln.Addr().(*net.TCPAddr).Port
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn, cfg)
+		}
+	}()
+	return s
+}
+
+func (s *testServer) serve(conn net.Conn, cfg *gossh.ServerConfig) {
+	_, chans, reqs, err := gossh.NewServerConn(conn, cfg)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	go gossh.DiscardRequests(reqs)
+
+	for nc := range chans {
+		switch nc.ChannelType() {
+		case "session":
+			go s.session(nc)
+		case "direct-tcpip":
+			go s.directTCPIP(nc)
+		default:
+			nc.Reject(gossh.UnknownChannelType, "unsupported")
+		}
+	}
+}
+
+func (s *testServer) session(nc gossh.NewChannel) {
+	ch, reqs, err := nc.Accept()
+	if err != nil {
+		return
+	}
+	defer ch.Close()
+
+	resized := make(chan struct{}, 1)
+	for req := range reqs {
+		switch req.Type {
+		case "pty-req":
+			var pty struct {
+				Term                  string
+				Width, Height, PX, PY uint32
+				Modes                 string
+			}
+			_ = gossh.Unmarshal(req.Payload, &pty)
+			s.mu.Lock()
+			s.ptys = append(s.ptys, fmt.Sprintf("%s %dx%d", pty.Term, pty.Width, pty.Height))
+			s.mu.Unlock()
+			req.Reply(true, nil)
+		case "window-change":
+			var win struct{ Width, Height, PX, PY uint32 }
+			_ = gossh.Unmarshal(req.Payload, &win)
+			s.mu.Lock()
+			s.resizes = append(s.resizes, fmt.Sprintf("%dx%d", win.Width, win.Height))
+			s.mu.Unlock()
+			select {
+			case resized <- struct{}{}:
+			default:
+			}
+		case "exec":
+			var exec struct{ Command string }
+			_ = gossh.Unmarshal(req.Payload, &exec)
+			req.Reply(true, nil)
+
+			code := 0
+			if n, ok := strings.CutPrefix(exec.Command, "exit "); ok {
+				code, _ = strconv.Atoi(n)
+			}
+			fmt.Fprintf(ch, "ran %s\n", exec.Command)
+			sendExitStatus(ch, code)
+			return
+		case "shell":
+			req.Reply(true, nil)
+			go func() {
+				select {
+				case <-resized:
+					fmt.Fprintln(ch, "resized")
+				case <-time.After(5 * time.Second):
+				}
+				sendExitStatus(ch, 0)
+				ch.Close()
+			}()
+		default:
+			if req.WantReply {
+				req.Reply(false, nil)
+			}
+		}
+	}
+}
+
+func (s *testServer) directTCPIP(nc gossh.NewChannel) {
+	var target struct {
+		Host       string
+		Port       uint32
+		OriginHost string
+		OriginPort uint32
+	}
+	_ = gossh.Unmarshal(nc.ExtraData(), &target)
+
+	conn, err := net.Dial("tcp", net.JoinHostPort(target.Host, strconv.Itoa(int(target.Port))))
+	if err != nil {
+		nc.Reject(gossh.ConnectionFailed, err.Error())
+		return
+	}
+	ch, reqs, err := nc.Accept()
+	if err != nil {
+		conn.Close()
+		return
+	}
+	go gossh.DiscardRequests(reqs)
+
+	go func() {
+		_, _ = io.Copy(ch, conn)
+		ch.Close()
+	}()
+	_, _ = io.Copy(conn, ch)
+	conn.Close()
+}
+
+func sendExitStatus(ch gossh.Channel, code int) {
+	_, _ = ch.SendRequest("exit-status", false, gossh.Marshal(struct{ Status uint32 }{uint32(code)}))
+}
+
+func newTestSigner(t *testing.T) gossh.Signer {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	signer, err := gossh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+	return signer
+}
+
+// nativeTestEnv holds a client key and known_hosts file in a temporary home
+// directory, so the tests never touch the real ~/.ssh or ssh agent
+type nativeTestEnv struct {
+	dir        string
+	key        ed25519.PrivateKey
+	signer     gossh.Signer
+	keyPath    string
+	knownHosts string
+}
+
+func newNativeTestEnv(t *testing.T) *nativeTestEnv {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "sshto-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	t.Setenv("HOME", dir)
+	t.Setenv("USERPROFILE", dir)
+	t.Setenv("SSH_AUTH_SOCK", "")
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	signer, err := gossh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+	block, err := gossh.MarshalPrivateKey(key, "")
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+	keyPath := filepath.Join(dir, "id_test")
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+
+	return &nativeTestEnv{
+		dir:        dir,
+		key:        key,
+		signer:     signer,
+		keyPath:    keyPath,
+		knownHosts: filepath.Join(dir, "known_hosts"),
+	}
+}
+
+// trust adds the server's host key to the known_hosts file
+func (e *nativeTestEnv) trust(t *testing.T, s *testServer, key gossh.PublicKey) {
+	t.Helper()
+	line := knownhosts.Line([]string{knownhosts.Normalize(s.addr)}, key)
+	f, err := os.OpenFile(e.knownHosts, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatalf("Failed to open known_hosts: %v", err)
+	}
+	defer f.Close()
+	fmt.Fprintln(f, line)
+}
+
+// server returns the config of a server using the native transport
+func (e *nativeTestEnv) server(s *testServer) *config.Server {
+	return &config.Server{
+		Name:      "test",
+		Host:      "127.0.0.1",
+		Port:      s.port,
+		User:      "tester",
+		Key:       e.keyPath,
+		Transport: config.TransportNative,
+		Options:   map[string]string{"UserKnownHostsFile": e.knownHosts},
+	}
+}
+
+func TestNativeRun(t *testing.T) {
+	env := newNativeTestEnv(t)
+	ts := newTestServer(t, env.signer.PublicKey())
+	env.trust(t, ts, ts.hostKey.PublicKey())
+	client := NewClient()
+
+	var stdout, stderr bytes.Buffer
+	if err := client.Run(env.server(ts), "echo hi", &stdout, &stderr); err != nil {
+		t.Fatalf("Run() error = %v, stderr %q", err, stderr.String())
+	}
+	if stdout.String() != "ran echo hi\n" {
+		t.Errorf("Run() output = %q, want %q", stdout.String(), "ran echo hi\n")
+	}
+
+	err := client.Run(env.server(ts), "exit 3", io.Discard, io.Discard)
+	if code, ran := ExitStatus(err); code != 3 || !ran {
+		t.Errorf("Run(exit 3) error = %v, want exit status 3", err)
+	}
+}
+
+func TestNativeTestConnection(t *testing.T) {
+	env := newNativeTestEnv(t)
+	ts := newTestServer(t, env.signer.PublicKey())
+	env.trust(t, ts, ts.hostKey.PublicKey())
+	client := NewClient()
+
+	if err := client.TestConnection(env.server(ts)); err != nil {
+		t.Errorf("TestConnection() error = %v", err)
+	}
+
+	// A server that doesn't accept the key
+	other := newTestServer(t, newTestSigner(t).PublicKey())
+	env.trust(t, other, other.hostKey.PublicKey())
+	err := client.TestConnection(env.server(other))
+	if err == nil || !strings.Contains(err.Error(), "Permission denied") {
+		t.Errorf("TestConnection() error = %v, want Permission denied", err)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	closed := env.server(ts)
+	closed.Port = ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+	err = client.TestConnection(closed)
+	if err == nil || !strings.Contains(err.Error(), "Connection refused") {
+		t.Errorf("TestConnection() error = %v, want Connection refused", err)
+	}
+}
+
+func TestNativeHostKeys(t *testing.T) {
+	env := newNativeTestEnv(t)
+	ts := newTestServer(t, env.signer.PublicKey())
+	client := NewClient()
+
+	err := client.TestConnection(env.server(ts))
+	if err == nil || !strings.Contains(err.Error(), "Host key verification failed") {
+		t.Errorf("TestConnection() to an unknown host error = %v, want Host key verification failed", err)
+	}
+
+	server := env.server(ts)
+	server.Options["StrictHostKeyChecking"] = "accept-new"
+	if err := client.TestConnection(server); err != nil {
+		t.Fatalf("TestConnection() with accept-new error = %v", err)
+	}
+	data, _ := os.ReadFile(env.knownHosts)
+	if !strings.Contains(string(data), knownhosts.Normalize(ts.addr)) {
+		t.Errorf("known_hosts = %q, want an entry for %s", data, ts.addr)
+	}
+	// The host is known now
+	if err := client.TestConnection(env.server(ts)); err != nil {
+		t.Errorf("TestConnection() to an added host error = %v", err)
+	}
+
+	impostor := newTestServer(t, env.signer.PublicKey())
+	env.trust(t, impostor, newTestSigner(t).PublicKey())
+	err = client.TestConnection(env.server(impostor))
+	if err == nil || !strings.Contains(err.Error(), "REMOTE HOST IDENTIFICATION HAS CHANGED") {
+		t.Errorf("TestConnection() with a changed host key error = %v, want a changed key error", err)
+	}
+}
+
+func TestNativeTerminal(t *testing.T) {
+	env := newNativeTestEnv(t)
+	ts := newTestServer(t, env.signer.PublicKey())
+	env.trust(t, ts, ts.hostKey.PublicKey())
+	client := NewClient()
+
+	resize := make(chan size, 1)
+	resize <- size{100, 30}
+	close(resize)
+
+	var out bytes.Buffer
+	tty := &terminal{term: "xterm", size: size{80, 24}, resize: resize}
+	if err := client.runNative(env.server(ts), time.Second, strings.NewReader(""), &out, io.Discard, tty); err != nil {
+		t.Fatalf("runNative() error = %v", err)
+	}
+	if out.String() != "resized\n" {
+		t.Errorf("runNative() output = %q, want %q", out.String(), "resized\n")
+	}
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if len(ts.ptys) != 1 || ts.ptys[0] != "xterm 80x24" {
+		t.Errorf("terminal requests = %q, want [xterm 80x24]", ts.ptys)
+	}
+	if len(ts.resizes) != 1 || ts.resizes[0] != "100x30" {
+		t.Errorf("window changes = %q, want [100x30]", ts.resizes)
+	}
+}
+
+func TestNativeJumpHost(t *testing.T) {
+	env := newNativeTestEnv(t)
+	bastion := newTestServer(t, env.signer.PublicKey())
+	target := newTestServer(t, env.signer.PublicKey())
+	env.trust(t, bastion, bastion.hostKey.PublicKey())
+	env.trust(t, target, target.hostKey.PublicKey())
+
+	server := env.server(target)
+	server.JumpChain = []config.Server{*env.server(bastion)}
+
+	var out bytes.Buffer
+	if err := NewClient().Run(server, "hostname", &out, io.Discard); err != nil {
+		t.Fatalf("Run() through a jump host error = %v", err)
+	}
+	if out.String() != "ran hostname\n" {
+		t.Errorf("Run() output = %q", out.String())
+	}
+}
+
+func TestNativeAgent(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("ssh agent tests need unix sockets")
+	}
+	env := newNativeTestEnv(t)
+	ts := newTestServer(t, env.signer.PublicKey())
+	env.trust(t, ts, ts.hostKey.PublicKey())
+
+	keyring := agent.NewKeyring()
+	if err := keyring.Add(agent.AddedKey{PrivateKey: env.key}); err != nil {
+		t.Fatalf("Failed to add key to agent: %v", err)
+	}
+	sock := filepath.Join(env.dir, "agent.sock")
+	ln, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go agent.ServeAgent(keyring, conn)
+		}
+	}()
+	t.Setenv("SSH_AUTH_SOCK", sock)
+
+	server := env.server(ts)
+	server.Key = ""
+	if err := NewClient().TestConnection(server); err != nil {
+		t.Errorf("TestConnection() with an agent key error = %v", err)
+	}
+}
+
+func TestNativeUnsupported(t *testing.T) {
+	client := NewClient()
+	server := &config.Server{
+		Host:      "10.0.0.1",
+		Transport: config.TransportNative,
+		Forwards:  []config.Forward{{Type: config.ForwardDynamic, Listen: "1080"}},
+	}
+
+	if err := client.Connect(server); err == nil || !strings.Contains(err.Error(), "forwards") {
+		t.Errorf("Connect() with forwards error = %v", err)
+	}
+	if _, err := client.StartTunnel(server, io.Discard); err == nil {
+		t.Error("StartTunnel() expected error, got nil")
+	}
+	if err := client.Copy(server, []Location{{Path: "a"}}, Location{Path: "b", Remote: true}, CopyOptions{}); err == nil {
+		t.Error("Copy() expected error, got nil")
+	}
+}
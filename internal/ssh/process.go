@@ -0,0 +1,70 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+)
+
+// ExitError reports that ssh, or a program run in its place such as scp,
+// exited with a non-zero status. For a remote command that is the status of
+// the command itself.
+type ExitError struct {
+	Program string
+	Code    int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("%s exited with status %d", e.Program, e.Code)
+}
+
+// runAttached runs cmd in the foreground until it exits. The signals listed
+// in forwardedSignals are passed on to it instead of stopping sshto, so that
+// ssh decides how to end the session and sshto can report how it ended.
+func runAttached(cmd *exec.Cmd) error {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, forwardedSignals...)
+	defer signal.Stop(sigs)
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case sig := <-sigs:
+				forwardSignal(cmd.Process, sig)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	err := cmd.Wait()
+	close(done)
+	return exitError(cmd, err)
+}
+
+// exitError turns the error of a program that ran and failed into an
+// ExitError. A program killed by a signal gets the shell's 128+n status.
+func exitError(cmd *exec.Cmd, err error) error {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return err
+	}
+
+	code := exitErr.ExitCode()
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		code = 128 + int(status.Signal())
+	}
+	if code < 0 {
+		return err
+	}
+	return &ExitError{Program: filepath.Base(cmd.Path), Code: code}
+}
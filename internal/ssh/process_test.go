@@ -0,0 +1,35 @@
+package ssh
+
+import (
+	"errors"
+	"os/exec"
+	"runtime"
+	"testing"
+)
+
+func TestRunAttachedExitError(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+
+	if err := runAttached(exec.Command("sh", "-c", "exit 0")); err != nil {
+		t.Errorf("runAttached(exit 0) error = %v", err)
+	}
+
+	err := runAttached(exec.Command("sh", "-c", "exit 3"))
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 3 || exitErr.Program != "sh" {
+		t.Fatalf("runAttached(exit 3) error = %v, want *ExitError with code 3", err)
+	}
+	if exitErr.Error() != "sh exited with status 3" {
+		t.Errorf("Error() = %q", exitErr.Error())
+	}
+	if code, ran := ExitStatus(err); code != 3 || !ran {
+		t.Errorf("ExitStatus() = %d, %v, want 3, true", code, ran)
+	}
+
+	err = runAttached(exec.Command("/nonexistent/binary"))
+	if err == nil || errors.As(err, &exitErr) {
+		t.Errorf("runAttached() of a missing program error = %v, want a start error", err)
+	}
+}
@@ -0,0 +1,29 @@
+package ssh
+
+import (
+	"regexp"
+	"strings"
+)
+
+// safeShellWord matches words that need no quoting in a POSIX shell
+var safeShellWord = regexp.MustCompile(`^[A-Za-z0-9@%+=:,./_-]+$`)
+
+// shellQuote quotes s for a POSIX shell when necessary
+func shellQuote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	if safeShellWord.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// shellJoin quotes and joins args into a single command line
+func shellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = shellQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
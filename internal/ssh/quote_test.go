@@ -0,0 +1,35 @@
+package ssh
+
+import "testing"
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected string
+	}{
+		{"", "''"},
+		{"simple", "simple"},
+		{"admin@host:22", "admin@host:22"},
+		{"%h:%p", "%h:%p"},
+		{"has space", "'has space'"},
+		{"it's", `'it'\''s'`},
+		{"$HOME", "'$HOME'"},
+		{"~/key", "'~/key'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := shellQuote(tt.in); got != tt.expected {
+				t.Errorf("shellQuote(%q) = %q, want %q", tt.in, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestShellJoin(t *testing.T) {
+	got := shellJoin([]string{"ssh", "-o", "ProxyCommand=ssh -W %h:%p bastion", "host"})
+	expected := "ssh -o 'ProxyCommand=ssh -W %h:%p bastion' host"
+	if got != expected {
+		t.Errorf("shellJoin() = %q, want %q", got, expected)
+	}
+}
@@ -0,0 +1,43 @@
+//go:build !windows
+
+package ssh
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"golang.org/x/term"
+)
+
+// watchResize reports the new size of the terminal fd each time it changes,
+// until stop is called
+func watchResize(fd int) (<-chan size, func()) {
+	sizes := make(chan size, 1)
+	sigs := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(sigs, syscall.SIGWINCH)
+
+	go func() {
+		defer close(sizes)
+		for {
+			select {
+			case <-sigs:
+				if width, height, err := term.GetSize(fd); err == nil {
+					select {
+					case sizes <- size{width, height}:
+					case <-done:
+						return
+					}
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return sizes, func() {
+		signal.Stop(sigs)
+		close(done)
+	}
+}
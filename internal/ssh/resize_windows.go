@@ -0,0 +1,43 @@
+//go:build windows
+
+package ssh
+
+import (
+	"time"
+
+	"golang.org/x/term"
+)
+
+// watchResize reports the new size of the console fd each time it changes,
+// until stop is called. Windows has no SIGWINCH, so the size is polled.
+func watchResize(fd int) (<-chan size, func()) {
+	sizes := make(chan size, 1)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(sizes)
+		tick := time.NewTicker(250 * time.Millisecond)
+		defer tick.Stop()
+
+		last := size{}
+		last.width, last.height, _ = term.GetSize(fd)
+		for {
+			select {
+			case <-tick.C:
+				width, height, err := term.GetSize(fd)
+				if err == nil && (size{width, height}) != last {
+					last = size{width, height}
+					select {
+					case sizes <- last:
+					case <-done:
+						return
+					}
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return sizes, func() { close(done) }
+}
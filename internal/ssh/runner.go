@@ -0,0 +1,68 @@
+package ssh
+
+import (
+	"io"
+	"os/exec"
+)
+
+// Command is a program for a Runner to run
+type Command struct {
+	Name   string // program, e.g. the ssh binary
+	Args   []string
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// Attached marks a foreground session: signals sent to sshto are
+	// relayed to the program instead of stopping sshto
+	Attached bool
+}
+
+// Process is a program started in the background
+type Process struct {
+	PID  int
+	Wait func() error // waits for the program to exit
+}
+
+// Runner runs the programs a Client invokes. Errors for programs that ran
+// and exited with a non-zero status are *ExitError.
+type Runner interface {
+	// Run runs cmd to completion
+	Run(cmd *Command) error
+
+	// Start starts cmd in the background, detached from the terminal
+	Start(cmd *Command) (*Process, error)
+}
+
+// ExecRunner runs programs as local processes
+type ExecRunner struct{}
+
+// Run implements Runner
+func (ExecRunner) Run(c *Command) error {
+	cmd := exec.Command(c.Name, c.Args...)
+	cmd.Stdin = c.Stdin
+	cmd.Stdout = c.Stdout
+	cmd.Stderr = c.Stderr
+
+	if c.Attached {
+		return runAttached(cmd)
+	}
+	return exitError(cmd, cmd.Run())
+}
+
+// Start implements Runner
+func (ExecRunner) Start(c *Command) (*Process, error) {
+	cmd := exec.Command(c.Name, c.Args...)
+	cmd.Stdin = c.Stdin
+	cmd.Stdout = c.Stdout
+	cmd.Stderr = c.Stderr
+	cmd.SysProcAttr = detachedProcAttr()
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return &Process{
+		PID:  cmd.Process.Pid,
+		Wait: func() error { return exitError(cmd, cmd.Wait()) },
+	}, nil
+}
@@ -0,0 +1,113 @@
+package ssh
+
+import (
+	"bytes"
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/codoworks/sshto/internal/config"
+)
+
+// recordingRunner records the commands it is asked to run and fails them with err
+type recordingRunner struct {
+	commands []*Command
+	err      error
+}
+
+func (r *recordingRunner) Run(cmd *Command) error {
+	r.commands = append(r.commands, cmd)
+	return r.err
+}
+
+func (r *recordingRunner) Start(cmd *Command) (*Process, error) {
+	r.commands = append(r.commands, cmd)
+	return &Process{PID: 1, Wait: func() error { return r.err }}, r.err
+}
+
+func TestClientUsesRunner(t *testing.T) {
+	runner := &recordingRunner{}
+	client := &Client{Runner: runner, SSHBinary: "/opt/corp/ssh", SCPBinary: "/opt/corp/scp"}
+	server := &config.Server{
+		Host:      "10.0.0.1",
+		User:      "deploy",
+		JumpChain: []config.Server{{Host: "bastion.example.com", Key: "/keys/bastion"}},
+	}
+
+	if err := client.Connect(server); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	if err := client.Copy(server, []Location{{Path: "app.tar.gz"}}, Location{Path: "/tmp/", Remote: true}, CopyOptions{}); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if _, err := client.StartTunnel(server, &bytes.Buffer{}); err != nil {
+		t.Fatalf("StartTunnel() error = %v", err)
+	}
+
+	if len(runner.commands) != 3 {
+		t.Fatalf("ran %d commands, want 3", len(runner.commands))
+	}
+	connect, cp, tunnel := runner.commands[0], runner.commands[1], runner.commands[2]
+
+	if connect.Name != "/opt/corp/ssh" || !connect.Attached {
+		t.Errorf("Connect() ran %q (attached %v), want /opt/corp/ssh attached", connect.Name, connect.Attached)
+	}
+	// Jump hosts reached through a ProxyCommand use the configured binary too
+	proxy := "ProxyCommand=/opt/corp/ssh -i /keys/bastion -W %h:%p bastion.example.com"
+	if got := strings.Join(connect.Args, " "); got != "-o "+proxy+" deploy@10.0.0.1" {
+		t.Errorf("Connect() args = %q", got)
+	}
+
+	if cp.Name != "/opt/corp/scp" || !cp.Attached {
+		t.Errorf("Copy() ran %q (attached %v), want /opt/corp/scp attached", cp.Name, cp.Attached)
+	}
+	if tunnel.Name != "/opt/corp/ssh" || tunnel.Attached {
+		t.Errorf("StartTunnel() ran %q (attached %v), want /opt/corp/ssh in the background", tunnel.Name, tunnel.Attached)
+	}
+
+	rsync := client.CopyCommand(server, []Location{{Path: "a"}}, Location{Path: "b", Remote: true}, CopyOptions{Rsync: true})
+	if len(rsync) < 4 || !strings.HasPrefix(rsync[3], "/opt/corp/ssh ") {
+		t.Errorf("CopyCommand(rsync) = %q, want -e to use /opt/corp/ssh", rsync)
+	}
+}
+
+func TestTestConnectionUsesRunner(t *testing.T) {
+	runner := &recordingRunner{err: &ExitError{Program: "ssh", Code: 255}}
+	client := &Client{Runner: runner}
+
+	err := client.TestConnection(&config.Server{Host: "10.0.0.1"})
+	if err == nil {
+		t.Fatal("TestConnection() expected error, got nil")
+	}
+	if cmd := runner.commands[0]; cmd.Name != "ssh" || cmd.Stdout != cmd.Stderr {
+		t.Errorf("TestConnection() ran %q, want ssh with combined output", cmd.Name)
+	}
+}
+
+func TestExecRunner(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+
+	var out bytes.Buffer
+	err := ExecRunner{}.Run(&Command{Name: "sh", Args: []string{"-c", "echo hi; exit 2"}, Stdout: &out})
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 2 {
+		t.Errorf("Run() error = %v, want exit status 2", err)
+	}
+	if out.String() != "hi\n" {
+		t.Errorf("Run() output = %q, want %q", out.String(), "hi\n")
+	}
+
+	proc, err := ExecRunner{}.Start(&Command{Name: "sh", Args: []string{"-c", "exit 4"}})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if proc.PID <= 0 {
+		t.Errorf("Start() PID = %d", proc.PID)
+	}
+	if code, _ := ExitStatus(proc.Wait()); code != 4 {
+		t.Errorf("Wait() exit status = %d, want 4", code)
+	}
+}
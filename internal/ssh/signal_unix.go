@@ -0,0 +1,18 @@
+//go:build !windows
+
+package ssh
+
+import (
+	"os"
+	"syscall"
+)
+
+// forwardedSignals are relayed to ssh while it runs in the foreground.
+// Signals from the terminal reach ssh directly too; relaying them covers
+// signals sent to sshto alone, e.g. by kill or a process supervisor.
+var forwardedSignals = []os.Signal{syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGWINCH}
+
+// forwardSignal passes sig on to p
+func forwardSignal(p *os.Process, sig os.Signal) {
+	_ = p.Signal(sig)
+}
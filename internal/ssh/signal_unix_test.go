@@ -0,0 +1,43 @@
+//go:build !windows
+
+package ssh
+
+import (
+	"errors"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRunAttachedForwardsSignals(t *testing.T) {
+	cmd := exec.Command("sh", "-c", "trap 'exit 42' TERM; while :; do sleep 0.05; done")
+
+	result := make(chan error, 1)
+	go func() { result <- runAttached(cmd) }()
+
+	// Give the shell time to install its trap
+	time.Sleep(300 * time.Millisecond)
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatalf("Failed to signal: %v", err)
+	}
+
+	select {
+	case err := <-result:
+		var exitErr *ExitError
+		if !errors.As(err, &exitErr) || exitErr.Code != 42 {
+			t.Errorf("runAttached() error = %v, want exit status 42 from the trap", err)
+		}
+	case <-time.After(5 * time.Second):
+		_ = cmd.Process.Kill()
+		t.Fatal("SIGTERM was not forwarded")
+	}
+}
+
+func TestRunAttachedKilledBySignal(t *testing.T) {
+	err := runAttached(exec.Command("sh", "-c", "kill -KILL $$"))
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 128+int(syscall.SIGKILL) {
+		t.Errorf("runAttached() error = %v, want exit status %d", err, 128+int(syscall.SIGKILL))
+	}
+}
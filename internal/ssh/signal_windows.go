@@ -0,0 +1,13 @@
+//go:build windows
+
+package ssh
+
+import "os"
+
+// forwardedSignals are caught while ssh runs in the foreground. Windows
+// delivers Ctrl+C to every process attached to the console, so ssh already
+// has it; sshto only has to stay alive until ssh exits.
+var forwardedSignals = []os.Signal{os.Interrupt}
+
+// forwardSignal does nothing: the console has delivered the signal to ssh
+func forwardSignal(p *os.Process, sig os.Signal) {}
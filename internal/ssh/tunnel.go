@@ -0,0 +1,48 @@
+package ssh
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/codoworks/sshto/internal/config"
+)
+
+// StartTunnel starts a forward-only ssh process for the server's forwards in
+// the background, detached from the terminal and writing its output to log.
+// The caller should Wait on the returned process while it lives.
+func (c *Client) StartTunnel(server *config.Server, log io.Writer) (*Process, error) {
+	if native(server) {
+		return nil, fmt.Errorf("tunnels need the %s transport", config.TransportOpenSSH)
+	}
+	proc, err := c.runner().Start(&Command{
+		Name:   c.sshBinary(),
+		Args:   c.tunnelArgs(server),
+		Stdout: log,
+		Stderr: log,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("starting ssh: %w", err)
+	}
+	return proc, nil
+}
+
+// BuildTunnelCommand returns the ssh command StartTunnel runs, for display
+func (c *Client) BuildTunnelCommand(server *config.Server) string {
+	return shellJoin(append([]string{c.sshBinary()}, c.tunnelArgs(server)...))
+}
+
+// tunnelArgs constructs the arguments for a forward-only connection. ssh
+// exits when a forward can't be set up or the server stops answering,
+// so a tunnel that is running is a tunnel that works.
+func (c *Client) tunnelArgs(server *config.Server) []string {
+	args := hostArgs(server)
+	args = append(args, "-N",
+		"-o", "BatchMode=yes",
+		"-o", "ExitOnForwardFailure=yes",
+		"-o", "ServerAliveInterval=30",
+		"-o", "ServerAliveCountMax=3",
+	)
+	args = append(args, forwardArgs(server.Forwards)...)
+	args = append(args, c.jumpArgs(server.JumpChain)...)
+	return append(args, "--", destination(server))
+}
@@ -0,0 +1,37 @@
+package ssh
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/codoworks/sshto/internal/config"
+)
+
+func TestTunnelArgs(t *testing.T) {
+	client := NewClient()
+	server := &config.Server{
+		Host: "db.example.com",
+		User: "deploy",
+		Forwards: []config.Forward{
+			{Type: config.ForwardLocal, Listen: "5432", Target: "localhost:5432"},
+			{Type: config.ForwardDynamic, Listen: "1080"},
+		},
+		JumpChain: []config.Server{{Host: "bastion.example.com"}},
+	}
+
+	got := client.tunnelArgs(server)
+	want := []string{
+		"-N",
+		"-o", "BatchMode=yes",
+		"-o", "ExitOnForwardFailure=yes",
+		"-o", "ServerAliveInterval=30",
+		"-o", "ServerAliveCountMax=3",
+		"-L", "5432:localhost:5432",
+		"-D", "1080",
+		"-J", "bastion.example.com",
+		"--", "deploy@db.example.com",
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("tunnelArgs() = %v, want %v", got, want)
+	}
+}
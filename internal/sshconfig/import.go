@@ -0,0 +1,115 @@
+package sshconfig
+
+import (
+	"fmt"
+
+	"github.com/codoworks/sshto/internal/config"
+)
+
+// ConflictPolicy decides what happens when an imported name already exists
+type ConflictPolicy string
+
+const (
+	ConflictSkip      ConflictPolicy = "skip"
+	ConflictOverwrite ConflictPolicy = "overwrite"
+	ConflictRename    ConflictPolicy = "rename"
+)
+
+// ParseConflictPolicy validates a conflict policy name
+func ParseConflictPolicy(s string) (ConflictPolicy, error) {
+	switch p := ConflictPolicy(s); p {
+	case ConflictSkip, ConflictOverwrite, ConflictRename:
+		return p, nil
+	}
+	return "", fmt.Errorf("invalid conflict policy %q (use skip, overwrite or rename)", s)
+}
+
+// Action describes what an import will do with a single host
+type Action string
+
+const (
+	ActionAdd       Action = "add"
+	ActionSkip      Action = "skip"
+	ActionOverwrite Action = "overwrite"
+	ActionRename    Action = "rename"
+	ActionInvalid   Action = "invalid"
+)
+
+// ImportItem is a single planned change produced by PlanImport
+type ImportItem struct {
+	Alias  string
+	Server config.Server
+	Action Action
+	Reason string
+}
+
+// PlanImport works out how each host would be merged into cfg without modifying it
+func PlanImport(cfg *config.Config, hosts []Host, policy ConflictPolicy, group string) []ImportItem {
+	taken := make(map[string]bool)
+	for _, s := range cfg.Servers {
+		taken[s.Name] = true
+	}
+
+	items := make([]ImportItem, 0, len(hosts))
+	for _, h := range hosts {
+		item := ImportItem{Alias: h.Alias, Server: h.Server(), Action: ActionAdd}
+		item.Server.Group = group
+
+		if err := config.ValidateServer(&item.Server); err != nil {
+			item.Action = ActionInvalid
+			item.Reason = err.Error()
+			items = append(items, item)
+			continue
+		}
+
+		if taken[item.Server.Name] {
+			switch policy {
+			case ConflictOverwrite:
+				item.Action = ActionOverwrite
+			case ConflictRename:
+				item.Action = ActionRename
+				item.Server.Name = uniqueName(item.Server.Name, taken)
+				item.Reason = "renamed to " + item.Server.Name
+			default:
+				item.Action = ActionSkip
+				item.Reason = "already exists"
+			}
+		}
+
+		taken[item.Server.Name] = true
+		items = append(items, item)
+	}
+
+	return items
+}
+
+// ApplyImport writes the planned additions and overwrites into cfg
+func ApplyImport(cfg *config.Config, items []ImportItem) (int, error) {
+	applied := 0
+	for _, item := range items {
+		var err error
+		switch item.Action {
+		case ActionAdd, ActionRename:
+			err = cfg.AddServer(item.Server)
+		case ActionOverwrite:
+			err = cfg.UpdateServer(item.Server.Name, item.Server)
+		default:
+			continue
+		}
+		if err != nil {
+			return applied, err
+		}
+		applied++
+	}
+	return applied, nil
+}
+
+// uniqueName appends a numeric suffix until the name is free
+func uniqueName(name string, taken map[string]bool) string {
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s-%d", name, i)
+		if !taken[candidate] {
+			return candidate
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package sshconfig
+
+import (
+	"testing"
+
+	"github.com/codoworks/sshto/internal/config"
+)
+
+func TestParseConflictPolicy(t *testing.T) {
+	for _, s := range []string{"skip", "overwrite", "rename"} {
+		if _, err := ParseConflictPolicy(s); err != nil {
+			t.Errorf("ParseConflictPolicy(%q) error = %v", s, err)
+		}
+	}
+	if _, err := ParseConflictPolicy("merge"); err == nil {
+		t.Error("ParseConflictPolicy(merge) should return error")
+	}
+}
+
+func TestPlanImport(t *testing.T) {
+	hosts := []Host{
+		{Alias: "web1", HostName: "10.0.0.1"},
+		{Alias: "db1", HostName: "10.0.0.2"},
+		{Alias: "bad", HostName: "%h.example.com"},
+	}
+
+	tests := []struct {
+		name     string
+		policy   ConflictPolicy
+		action   Action
+		web1Name string
+	}{
+		{"skip", ConflictSkip, ActionSkip, "web1"},
+		{"overwrite", ConflictOverwrite, ActionOverwrite, "web1"},
+		{"rename", ConflictRename, ActionRename, "web1-2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				Servers: []config.Server{{Name: "web1", Host: "192.168.1.1"}},
+			}
+
+			items := PlanImport(cfg, hosts, tt.policy, "imported")
+			if len(items) != 3 {
+				t.Fatalf("PlanImport() returned %d items, want 3", len(items))
+			}
+			if items[0].Action != tt.action {
+				t.Errorf("web1 action = %q, want %q", items[0].Action, tt.action)
+			}
+			if items[0].Server.Name != tt.web1Name {
+				t.Errorf("web1 name = %q, want %q", items[0].Server.Name, tt.web1Name)
+			}
+			if items[1].Action != ActionAdd {
+				t.Errorf("db1 action = %q, want %q", items[1].Action, ActionAdd)
+			}
+			if items[1].Server.Group != "imported" {
+				t.Errorf("db1 group = %q, want %q", items[1].Server.Group, "imported")
+			}
+			if items[2].Action != ActionInvalid {
+				t.Errorf("bad action = %q, want %q", items[2].Action, ActionInvalid)
+			}
+
+			// Planning must not modify the config
+			if len(cfg.Servers) != 1 || cfg.Servers[0].Host != "192.168.1.1" {
+				t.Errorf("PlanImport() modified config: %+v", cfg.Servers)
+			}
+		})
+	}
+}
+
+func TestApplyImport(t *testing.T) {
+	cfg := &config.Config{
+		Servers: []config.Server{
+			{Name: "web1", Host: "192.168.1.1"},
+			{Name: "web2", Host: "192.168.1.2"},
+		},
+	}
+
+	hosts := []Host{
+		{Alias: "web1", HostName: "10.0.0.1"},
+		{Alias: "db1", HostName: "10.0.0.2"},
+	}
+
+	items := PlanImport(cfg, hosts, ConflictOverwrite, "")
+	applied, err := ApplyImport(cfg, items)
+	if err != nil {
+		t.Fatalf("ApplyImport() error = %v", err)
+	}
+	if applied != 2 {
+		t.Errorf("ApplyImport() applied %d, want 2", applied)
+	}
+	if len(cfg.Servers) != 3 {
+		t.Errorf("Servers count = %d, want 3", len(cfg.Servers))
+	}
+	if cfg.Servers[0].Host != "10.0.0.1" {
+		t.Errorf("Overwritten host = %q, want %q", cfg.Servers[0].Host, "10.0.0.1")
+	}
+}
+
+func TestPlanImportRenameAvoidsTakenSuffix(t *testing.T) {
+	cfg := &config.Config{
+		Servers: []config.Server{
+			{Name: "web", Host: "10.0.0.1"},
+			{Name: "web-2", Host: "10.0.0.2"},
+		},
+	}
+
+	items := PlanImport(cfg, []Host{{Alias: "web", HostName: "10.0.0.3"}}, ConflictRename, "")
+	if items[0].Server.Name != "web-3" {
+		t.Errorf("Renamed to %q, want %q", items[0].Server.Name, "web-3")
+	}
+}
@@ -0,0 +1,258 @@
+package sshconfig
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/codoworks/sshto/internal/config"
+)
+
+// maxIncludeDepth mirrors the recursion limit used by OpenSSH
+const maxIncludeDepth = 16
+
+// Host represents a single concrete Host entry from an ssh_config file
+type Host struct {
+	Alias        string
+	HostName     string
+	User         string
+	Port         int
+	IdentityFile string
+	ProxyJump    string
+	Forwards     []config.Forward
+}
+
+// DefaultPath returns the default user ssh_config path
+func DefaultPath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".ssh", "config")
+}
+
+// Parse reads an ssh_config file, following Include directives, and returns
+// every concrete host alias it defines. Wildcard and negated patterns are skipped.
+func Parse(path string) ([]Host, error) {
+	p := &parser{index: make(map[string]int)}
+	if err := p.parseFile(config.ExpandPath(path), 0); err != nil {
+		return nil, err
+	}
+	return p.hosts, nil
+}
+
+type parser struct {
+	hosts   []Host
+	index   map[string]int // alias -> position in hosts
+	current []int          // hosts targeted by the active Host block
+	inMatch bool           // inside a Match block, which we don't evaluate
+}
+
+func (p *parser) parseFile(path string, depth int) error {
+	if depth > maxIncludeDepth {
+		return fmt.Errorf("include depth exceeded at %s", path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("reading ssh config: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		key, args := splitLine(scanner.Text())
+		if key == "" {
+			continue
+		}
+
+		switch key {
+		case "host":
+			p.startHost(args)
+		case "match":
+			p.current = nil
+			p.inMatch = true
+		case "include":
+			for _, pattern := range args {
+				if err := p.include(path, pattern, depth); err != nil {
+					return err
+				}
+			}
+		default:
+			if err := p.apply(key, args); err != nil {
+				return fmt.Errorf("%s:%d: %w", path, lineNo, err)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading ssh config: %w", err)
+	}
+
+	return nil
+}
+
+func (p *parser) startHost(patterns []string) {
+	p.current = nil
+	p.inMatch = false
+
+	for _, pattern := range patterns {
+		if isWildcard(pattern) {
+			continue
+		}
+		i, ok := p.index[pattern]
+		if !ok {
+			i = len(p.hosts)
+			p.index[pattern] = i
+			p.hosts = append(p.hosts, Host{Alias: pattern})
+		}
+		p.current = append(p.current, i)
+	}
+}
+
+func (p *parser) include(parent, pattern string, depth int) error {
+	pattern = config.ExpandPath(pattern)
+	if !filepath.IsAbs(pattern) {
+		// Relative includes in user configs are resolved against ~/.ssh
+		home, _ := os.UserHomeDir()
+		pattern = filepath.Join(home, ".ssh", pattern)
+	}
+
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return fmt.Errorf("%s: invalid include pattern %q: %w", parent, pattern, err)
+	}
+
+	// Host and Match blocks opened by an included file end with that file
+	current, inMatch := p.current, p.inMatch
+	defer func() { p.current, p.inMatch = current, inMatch }()
+
+	for _, match := range matches {
+		p.current, p.inMatch = current, inMatch
+		if err := p.parseFile(match, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// apply sets a keyword on the hosts of the active block. As in OpenSSH,
+// the first value obtained for a keyword wins.
+func (p *parser) apply(key string, args []string) error {
+	if p.inMatch || len(p.current) == 0 || len(args) == 0 {
+		return nil
+	}
+
+	for _, i := range p.current {
+		h := &p.hosts[i]
+		switch key {
+		case "hostname":
+			if h.HostName == "" {
+				h.HostName = args[0]
+			}
+		case "user":
+			if h.User == "" {
+				h.User = args[0]
+			}
+		case "port":
+			if h.Port == 0 {
+				port, err := strconv.Atoi(args[0])
+				if err != nil {
+					return fmt.Errorf("invalid port %q", args[0])
+				}
+				h.Port = port
+			}
+		case "identityfile":
+			if h.IdentityFile == "" {
+				h.IdentityFile = args[0]
+			}
+		case "localforward", "remoteforward":
+			if len(args) == 2 {
+				typ := config.ForwardLocal
+				if key == "remoteforward" {
+					typ = config.ForwardRemote
+				}
+				h.Forwards = append(h.Forwards, config.Forward{Type: typ, Listen: args[0], Target: args[1]})
+			}
+		case "dynamicforward":
+			h.Forwards = append(h.Forwards, config.Forward{Type: config.ForwardDynamic, Listen: args[0]})
+		case "proxyjump":
+			if h.ProxyJump == "" {
+				h.ProxyJump = args[0]
+			}
+		}
+	}
+	return nil
+}
+
+// splitLine returns the lowercased keyword and its arguments for a config line.
+// Both "Key value" and "Key=value" forms are accepted, and double quotes group words.
+func splitLine(line string) (string, []string) {
+	var fields []string
+	var b strings.Builder
+	inQuote, inField, sawEquals := false, false, false
+
+	flush := func() {
+		if inField {
+			fields = append(fields, b.String())
+			b.Reset()
+			inField = false
+		}
+	}
+
+	for _, r := range strings.TrimSpace(line) {
+		if r == '#' && !inQuote && !inField {
+			break
+		}
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+			inField = true
+		case inQuote:
+			b.WriteRune(r)
+		case r == ' ' || r == '\t':
+			flush()
+		case r == '=' && !sawEquals && (len(fields) == 0 || (len(fields) == 1 && !inField)):
+			// A single '=' may separate the keyword from its value
+			sawEquals = true
+			flush()
+		default:
+			b.WriteRune(r)
+			inField = true
+		}
+	}
+	flush()
+
+	if len(fields) == 0 {
+		return "", nil
+	}
+	return strings.ToLower(fields[0]), fields[1:]
+}
+
+// isWildcard reports whether a Host pattern matches more than one literal name
+func isWildcard(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?!")
+}
+
+// Server converts the host entry into an sshto server definition
+func (h Host) Server() config.Server {
+	host := h.HostName
+	if host == "" {
+		host = h.Alias
+	}
+	jump := h.ProxyJump
+	if strings.EqualFold(jump, config.JumpNone) {
+		jump = ""
+	}
+	return config.Server{
+		Name: h.Alias,
+		Host: host,
+		User: h.User,
+		Port: h.Port,
+		Key:  h.IdentityFile,
+		Jump: jump,
+
+		Forwards: h.Forwards,
+	}
+}
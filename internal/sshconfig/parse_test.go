@@ -0,0 +1,206 @@
+package sshconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/codoworks/sshto/internal/config"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sshto-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	configPath := filepath.Join(tmpDir, "config")
+	writeFile(t, configPath, `# global settings
+Host *
+    User ignored
+
+Host web1 web2
+    HostName 192.168.1.10
+    User deploy
+    Port 2222
+    IdentityFile ~/.ssh/web_key
+    LocalForward 8080 localhost:80
+    DynamicForward 1080
+
+Host db1
+    ProxyJump bastion
+    HostName=db.internal # trailing comment
+    User = "postgres"
+    User other
+
+Host !bastion *.example.com
+    User nobody
+
+Match host foo
+    User matched
+`)
+
+	hosts, err := Parse(configPath)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if len(hosts) != 3 {
+		t.Fatalf("Parse() returned %d hosts, want 3: %+v", len(hosts), hosts)
+	}
+
+	web1 := hosts[0]
+	if web1.Alias != "web1" || web1.HostName != "192.168.1.10" || web1.User != "deploy" || web1.Port != 2222 || web1.IdentityFile != "~/.ssh/web_key" {
+		t.Errorf("web1 = %+v", web1)
+	}
+	if len(web1.Forwards) != 2 || web1.Forwards[0].Spec() != "8080:localhost:80" || web1.Forwards[1].Type != config.ForwardDynamic {
+		t.Errorf("web1.Forwards = %+v", web1.Forwards)
+	}
+	if hosts[1].Alias != "web2" || hosts[1].HostName != "192.168.1.10" {
+		t.Errorf("web2 = %+v", hosts[1])
+	}
+
+	db1 := hosts[2]
+	if db1.HostName != "db.internal" {
+		t.Errorf("db1.HostName = %q, want %q", db1.HostName, "db.internal")
+	}
+	if db1.ProxyJump != "bastion" {
+		t.Errorf("db1.ProxyJump = %q, want %q", db1.ProxyJump, "bastion")
+	}
+	if db1.User != "postgres" {
+		t.Errorf("db1.User = %q, want %q (first value wins)", db1.User, "postgres")
+	}
+}
+
+func TestParseInclude(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sshto-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := os.Mkdir(filepath.Join(tmpDir, "conf.d"), 0755); err != nil {
+		t.Fatalf("Failed to create conf.d: %v", err)
+	}
+	writeFile(t, filepath.Join(tmpDir, "conf.d", "a.conf"), "Host alpha\n    HostName 10.0.0.1\n")
+	writeFile(t, filepath.Join(tmpDir, "conf.d", "b.conf"), "Host beta\n    HostName 10.0.0.2\n")
+
+	configPath := filepath.Join(tmpDir, "config")
+	writeFile(t, configPath, "Include "+filepath.Join(tmpDir, "conf.d", "*.conf")+"\n\nHost gamma\n    HostName 10.0.0.3\n")
+
+	hosts, err := Parse(configPath)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := []string{"alpha", "beta", "gamma"}
+	if len(hosts) != len(want) {
+		t.Fatalf("Parse() returned %d hosts, want %d", len(hosts), len(want))
+	}
+	for i, name := range want {
+		if hosts[i].Alias != name {
+			t.Errorf("hosts[%d].Alias = %q, want %q", i, hosts[i].Alias, name)
+		}
+	}
+}
+
+func TestParseIncludeLoop(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sshto-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	configPath := filepath.Join(tmpDir, "config")
+	writeFile(t, configPath, "Include "+configPath+"\n")
+
+	if _, err := Parse(configPath); err == nil {
+		t.Error("Parse() should return error for recursive include")
+	}
+}
+
+func TestParseNonExistentFile(t *testing.T) {
+	if _, err := Parse("/nonexistent/ssh/config"); err == nil {
+		t.Error("Parse() should return error for non-existent file")
+	}
+}
+
+func TestParseInvalidPort(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sshto-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	configPath := filepath.Join(tmpDir, "config")
+	writeFile(t, configPath, "Host web\n    Port ssh\n")
+
+	if _, err := Parse(configPath); err == nil {
+		t.Error("Parse() should return error for non-numeric port")
+	}
+}
+
+func TestSplitLine(t *testing.T) {
+	tests := []struct {
+		line string
+		key  string
+		args []string
+	}{
+		{"", "", nil},
+		{"# comment", "", nil},
+		{"HostName example.com", "hostname", []string{"example.com"}},
+		{"  Port=2222", "port", []string{"2222"}},
+		{"User = admin", "user", []string{"admin"}},
+		{`IdentityFile "~/my keys/id"`, "identityfile", []string{"~/my keys/id"}},
+		{"Host a b # trailing", "host", []string{"a", "b"}},
+		{"SetEnv FOO=bar", "setenv", []string{"FOO=bar"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			key, args := splitLine(tt.line)
+			if key != tt.key {
+				t.Errorf("splitLine(%q) key = %q, want %q", tt.line, key, tt.key)
+			}
+			if len(args) != len(tt.args) {
+				t.Fatalf("splitLine(%q) args = %v, want %v", tt.line, args, tt.args)
+			}
+			for i := range args {
+				if args[i] != tt.args[i] {
+					t.Errorf("splitLine(%q) args[%d] = %q, want %q", tt.line, i, args[i], tt.args[i])
+				}
+			}
+		})
+	}
+}
+
+func TestHostServer(t *testing.T) {
+	h := Host{Alias: "web", HostName: "10.0.0.1", User: "deploy", Port: 2222, IdentityFile: "~/.ssh/key"}
+	s := h.Server()
+	if s.Name != "web" || s.Host != "10.0.0.1" || s.User != "deploy" || s.Port != 2222 || s.Key != "~/.ssh/key" {
+		t.Errorf("Server() = %+v", s)
+	}
+
+	s = Host{Alias: "web", HostName: "10.0.0.1", ProxyJump: "bastion"}.Server()
+	if s.Jump != "bastion" {
+		t.Errorf("Server().Jump = %q, want %q", s.Jump, "bastion")
+	}
+	s = Host{Alias: "web", ProxyJump: "none"}.Server()
+	if s.Jump != "" {
+		t.Errorf("Server().Jump = %q, want empty for ProxyJump none", s.Jump)
+	}
+
+	// Alias doubles as the host when HostName is missing
+	s = Host{Alias: "example.com"}.Server()
+	if s.Host != "example.com" {
+		t.Errorf("Server().Host = %q, want %q", s.Host, "example.com")
+	}
+}
@@ -0,0 +1,109 @@
+package sshconfig
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/codoworks/sshto/internal/config"
+)
+
+// managedHeader marks files written by sshto so they are never confused with hand-written ones
+const managedHeader = "# Managed by sshto. Changes will be overwritten by `sshto export ssh-config --write`."
+
+// ManagedPath returns the path of the generated ssh_config next to the sshto config
+func ManagedPath(configPath string) string {
+	return filepath.Join(filepath.Dir(configPath), "ssh_config")
+}
+
+// Render writes one Host block per server. Servers whose names cannot be used
+// as an ssh_config Host pattern are left out and returned.
+func Render(w io.Writer, servers []config.Server) (skipped []string, err error) {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, managedHeader)
+
+	for _, s := range servers {
+		if strings.ContainsAny(s.Name, " \t*?!,\"") {
+			skipped = append(skipped, s.Name)
+			continue
+		}
+
+		fmt.Fprintf(bw, "\nHost %s\n", s.Name)
+		writeOption(bw, "HostName", s.Host)
+		writeOption(bw, "User", s.User)
+		if s.Port != 0 {
+			writeOption(bw, "Port", strconv.Itoa(s.Port))
+		}
+		writeOption(bw, "IdentityFile", s.Key)
+		for _, f := range s.Forwards {
+			switch f.Type {
+			case config.ForwardLocal:
+				writeOption(bw, "LocalForward", f.Listen, f.Target)
+			case config.ForwardRemote:
+				writeOption(bw, "RemoteForward", f.Listen, f.Target)
+			case config.ForwardDynamic:
+				writeOption(bw, "DynamicForward", f.Listen)
+			}
+		}
+		for _, key := range config.SortedOptionKeys(s.Options) {
+			writeOption(bw, key, s.Options[key])
+		}
+		if len(s.JumpChain) > 0 {
+			// Hops named after sshto servers resolve to their own Host blocks
+			writeOption(bw, "ProxyJump", s.Jump)
+		}
+	}
+
+	return skipped, bw.Flush()
+}
+
+// writeOption writes a keyword line, quoting arguments that contain whitespace
+func writeOption(w io.Writer, key string, args ...string) {
+	if len(args) == 0 || args[0] == "" {
+		return
+	}
+	for i, arg := range args {
+		if strings.ContainsAny(arg, " \t") {
+			args[i] = `"` + arg + `"`
+		}
+	}
+	fmt.Fprintf(w, "    %s %s\n", key, strings.Join(args, " "))
+}
+
+// WriteManaged renders servers into path, refusing to replace a file that
+// sshto did not generate unless force is set.
+func WriteManaged(path string, servers []config.Server, force bool) (skipped []string, err error) {
+	if !force {
+		existing, err := os.ReadFile(path)
+		if err == nil && !bytes.HasPrefix(existing, []byte(managedHeader)) {
+			return nil, fmt.Errorf("%s was not generated by sshto (use --force to overwrite)", path)
+		}
+	}
+
+	var buf bytes.Buffer
+	skipped, err = Render(&buf, servers)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("creating directory: %w", err)
+	}
+
+	// Write to a temporary file first so ssh never reads a half-written config
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return nil, fmt.Errorf("writing ssh config: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return nil, fmt.Errorf("writing ssh config: %w", err)
+	}
+
+	return skipped, nil
+}
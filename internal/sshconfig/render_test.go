@@ -0,0 +1,115 @@
+package sshconfig
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/codoworks/sshto/internal/config"
+)
+
+func TestRender(t *testing.T) {
+	servers := []config.Server{
+		{Name: "web1", Host: "192.168.1.1", User: "admin", Port: 2222, Key: "~/.ssh/web key", Forwards: []config.Forward{
+			{Type: config.ForwardLocal, Listen: "5432", Target: "localhost:5432"},
+			{Type: config.ForwardRemote, Listen: "9000", Target: "localhost:9000"},
+			{Type: config.ForwardDynamic, Listen: "1080"},
+		}, Options: map[string]string{"StrictHostKeyChecking": "no", "ForwardAgent": "yes"}},
+		{Name: "db1", Host: "192.168.1.2", Jump: "web1", JumpChain: []config.Server{{Host: "192.168.1.1"}}},
+		{Name: "bad name", Host: "192.168.1.3"},
+	}
+
+	var b strings.Builder
+	skipped, err := Render(&b, servers)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	if len(skipped) != 1 || skipped[0] != "bad name" {
+		t.Errorf("Render() skipped = %v, want [bad name]", skipped)
+	}
+
+	expected := managedHeader + `
+
+Host web1
+    HostName 192.168.1.1
+    User admin
+    Port 2222
+    IdentityFile "~/.ssh/web key"
+    LocalForward 5432 localhost:5432
+    RemoteForward 9000 localhost:9000
+    DynamicForward 1080
+    ForwardAgent yes
+    StrictHostKeyChecking no
+
+Host db1
+    HostName 192.168.1.2
+    ProxyJump web1
+`
+	if b.String() != expected {
+		t.Errorf("Render() =\n%s\nwant\n%s", b.String(), expected)
+	}
+}
+
+func TestRenderRoundTrip(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sshto-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	path := filepath.Join(tmpDir, "ssh_config")
+	servers := []config.Server{
+		{Name: "web1", Host: "192.168.1.1", User: "admin", Port: 2222, Key: "/tmp/key"},
+	}
+
+	if _, err := WriteManaged(path, servers, false); err != nil {
+		t.Fatalf("WriteManaged() error = %v", err)
+	}
+
+	hosts, err := Parse(path)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(hosts) != 1 {
+		t.Fatalf("Parse() returned %d hosts, want 1", len(hosts))
+	}
+	got, want := hosts[0].Server(), servers[0]
+	if got.Name != want.Name || got.Host != want.Host || got.User != want.User || got.Port != want.Port || got.Key != want.Key {
+		t.Errorf("Round trip = %+v, want %+v", got, want)
+	}
+}
+
+func TestWriteManagedRefusesForeignFile(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sshto-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	path := filepath.Join(tmpDir, "ssh_config")
+	writeFile(t, path, "Host handwritten\n")
+
+	servers := []config.Server{{Name: "web1", Host: "192.168.1.1"}}
+
+	if _, err := WriteManaged(path, servers, false); err == nil {
+		t.Error("WriteManaged() should refuse to overwrite a file sshto did not create")
+	}
+
+	if _, err := WriteManaged(path, servers, true); err != nil {
+		t.Fatalf("WriteManaged(force) error = %v", err)
+	}
+
+	// Once managed, subsequent writes don't need force
+	if _, err := WriteManaged(path, servers, false); err != nil {
+		t.Errorf("WriteManaged() on managed file error = %v", err)
+	}
+}
+
+func TestManagedPath(t *testing.T) {
+	got := ManagedPath("/home/user/.config/sshto/config.yaml")
+	if got != "/home/user/.config/sshto/ssh_config" {
+		t.Errorf("ManagedPath() = %q", got)
+	}
+}
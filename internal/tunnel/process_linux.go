@@ -0,0 +1,26 @@
+//go:build linux
+
+package tunnel
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// processStart returns the start time of a process in clock ticks since
+// boot, read from /proc, or "" if it cannot be read
+func processStart(pid int) string {
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return ""
+	}
+	// The command name in parentheses may contain spaces, so fields are
+	// counted from the closing parenthesis: starttime is the 22nd field
+	stat := string(data)
+	fields := strings.Fields(stat[strings.LastIndexByte(stat, ')')+1:])
+	if len(fields) < 20 {
+		return ""
+	}
+	return fields[19]
+}
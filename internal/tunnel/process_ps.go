@@ -0,0 +1,19 @@
+//go:build !linux && !windows
+
+package tunnel
+
+import (
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// processStart returns the start time of a process as reported by ps, or ""
+// if it cannot be read
+func processStart(pid int) string {
+	out, err := exec.Command("ps", "-o", "lstart=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,24 @@
+//go:build !windows
+
+package tunnel
+
+import (
+	"errors"
+	"syscall"
+)
+
+// processAlive reports whether a process with the given pid exists
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	// Signal 0 checks for the process without touching it; EPERM means it
+	// exists but belongs to another user
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// terminate asks a process to exit
+func terminate(pid int) error {
+	return syscall.Kill(pid, syscall.SIGTERM)
+}
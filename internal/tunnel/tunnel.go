@@ -0,0 +1,239 @@
+package tunnel
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/codoworks/sshto/internal/config"
+)
+
+// Health is how well a running tunnel is doing
+type Health string
+
+const (
+	HealthUp       Health = "up"       // the process is running and every local port accepts connections
+	HealthDegraded Health = "degraded" // the process is running but a local port does not answer
+	HealthDown     Health = "down"     // the process is gone
+)
+
+// Tunnel is a background ssh process holding a server's forwards open
+type Tunnel struct {
+	Server       string           `yaml:"server"`
+	PID          int              `yaml:"pid"`
+	ProcessStart string           `yaml:"process_start,omitempty"` // tells the process from a later one given the same pid
+	Forwards     []config.Forward `yaml:"forwards"`
+	Started      time.Time        `yaml:"started"`
+	Log          string           `yaml:"log,omitempty"`
+}
+
+// Alive reports whether the tunnel's process is still running. Once ssh has
+// exited its pid may be reused, so the process must also have started when
+// the tunnel's did.
+func (t Tunnel) Alive() bool {
+	if !processAlive(t.PID) {
+		return false
+	}
+	return t.ProcessStart == "" || processStart(t.PID) == t.ProcessStart
+}
+
+// ProcessStart returns when the process with the given pid started, in a form
+// only meant for comparison, or "" if it cannot be read
+func ProcessStart(pid int) string {
+	if pid <= 0 {
+		return ""
+	}
+	return processStart(pid)
+}
+
+// Stop asks the tunnel's process to exit. A process that merely reuses the
+// tunnel's pid is left alone.
+func (t Tunnel) Stop() error {
+	if !t.Alive() {
+		return nil
+	}
+	if err := terminate(t.PID); err != nil {
+		return fmt.Errorf("stopping tunnel to %s (pid %d): %w", t.Server, t.PID, err)
+	}
+	return nil
+}
+
+// LocalAddresses returns the addresses the tunnel listens on locally.
+// Remote forwards listen on the server and are left out.
+func (t Tunnel) LocalAddresses() []string {
+	var addrs []string
+	for _, f := range t.Forwards {
+		if f.Type == config.ForwardLocal || f.Type == config.ForwardDynamic {
+			addrs = append(addrs, f.ListenAddress())
+		}
+	}
+	return addrs
+}
+
+// Check returns the tunnel's health, dialing each local port with the given timeout
+func (t Tunnel) Check(timeout time.Duration) Health {
+	if !t.Alive() {
+		return HealthDown
+	}
+	for _, addr := range t.LocalAddresses() {
+		conn, err := net.DialTimeout("tcp", addr, timeout)
+		if err != nil {
+			return HealthDegraded
+		}
+		conn.Close()
+	}
+	return HealthUp
+}
+
+// Store keeps the state of running tunnels, one file per server
+type Store struct {
+	dir string
+}
+
+// Dir returns the tunnel state directory next to the given config file
+func Dir(configPath string) string {
+	return filepath.Join(filepath.Dir(configPath), "tunnels")
+}
+
+// NewStore returns a store keeping its state in dir
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Dir returns the store's state directory
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// LogPath returns the file a server's tunnel writes its ssh output to
+func (s *Store) LogPath(server string) string {
+	return filepath.Join(s.dir, fileName(server)+".log")
+}
+
+// Get returns the tunnel recorded for server, or nil if there is none
+func (s *Store) Get(server string) (*Tunnel, error) {
+	t, err := s.read(s.statePath(server))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return t, err
+}
+
+// List returns every recorded tunnel sorted by server name. A missing
+// state directory means no tunnels.
+func (s *Store) List() ([]Tunnel, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading tunnels: %w", err)
+	}
+
+	var tunnels []Tunnel
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".yaml") {
+			continue
+		}
+		t, err := s.read(filepath.Join(s.dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		tunnels = append(tunnels, *t)
+	}
+
+	sort.Slice(tunnels, func(i, j int) bool { return tunnels[i].Server < tunnels[j].Server })
+	return tunnels, nil
+}
+
+// Save records a tunnel, replacing any earlier record for the same server
+func (s *Store) Save(t Tunnel) error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("creating tunnel directory: %w", err)
+	}
+
+	data, err := yaml.Marshal(t)
+	if err != nil {
+		return fmt.Errorf("marshaling tunnel: %w", err)
+	}
+
+	path := s.statePath(t.Server)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("writing tunnel: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("writing tunnel: %w", err)
+	}
+	return nil
+}
+
+// Remove deletes the record of a server's tunnel. Its log is kept.
+func (s *Store) Remove(server string) error {
+	if err := os.Remove(s.statePath(server)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("removing tunnel: %w", err)
+	}
+	return nil
+}
+
+// Prune removes the records of tunnels whose process is gone and returns them
+func (s *Store) Prune() ([]Tunnel, error) {
+	tunnels, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var stale []Tunnel
+	for _, t := range tunnels {
+		if t.Alive() {
+			continue
+		}
+		if err := s.Remove(t.Server); err != nil {
+			return stale, err
+		}
+		stale = append(stale, t)
+	}
+	return stale, nil
+}
+
+func (s *Store) statePath(server string) string {
+	return filepath.Join(s.dir, fileName(server)+".yaml")
+}
+
+func (s *Store) read(path string) (*Tunnel, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("reading tunnel: %w", err)
+	}
+
+	var t Tunnel
+	if err := yaml.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("parsing tunnel %s: %w", filepath.Base(path), err)
+	}
+	return &t, nil
+}
+
+// fileName escapes a server name for use as a file name on any platform.
+// Server names may contain characters such as "/" and ":".
+func fileName(server string) string {
+	var b strings.Builder
+	for _, c := range []byte(server) {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_', c == '.':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
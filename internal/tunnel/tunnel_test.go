@@ -0,0 +1,169 @@
+package tunnel
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/codoworks/sshto/internal/config"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "sshto-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return NewStore(dir)
+}
+
+func TestStoreSaveGetRemove(t *testing.T) {
+	s := newTestStore(t)
+
+	if got, err := s.Get("db"); err != nil || got != nil {
+		t.Fatalf("Get() on empty store = %v, %v, want nil, nil", got, err)
+	}
+
+	want := Tunnel{
+		Server:   "db",
+		PID:      os.Getpid(),
+		Forwards: []config.Forward{{Type: config.ForwardLocal, Listen: "5432", Target: "localhost:5432"}},
+		Started:  time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	if err := s.Save(want); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	got, err := s.Get("db")
+	if err != nil || got == nil {
+		t.Fatalf("Get() = %v, %v", got, err)
+	}
+	if got.PID != want.PID || !got.Started.Equal(want.Started) || len(got.Forwards) != 1 {
+		t.Errorf("Get() = %+v, want %+v", got, want)
+	}
+
+	if err := s.Remove("db"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if got, _ := s.Get("db"); got != nil {
+		t.Errorf("Get() after Remove() = %+v, want nil", got)
+	}
+	if err := s.Remove("db"); err != nil {
+		t.Errorf("Remove() of missing tunnel error = %v", err)
+	}
+}
+
+func TestStoreListMissingDir(t *testing.T) {
+	s := NewStore("/nonexistent/sshto/tunnels")
+	tunnels, err := s.List()
+	if err != nil || len(tunnels) != 0 {
+		t.Errorf("List() = %v, %v, want no tunnels", tunnels, err)
+	}
+}
+
+func TestStorePrune(t *testing.T) {
+	s := newTestStore(t)
+
+	// PID 0 never names a running process, and a process that started at
+	// another time only reuses the pid of the tunnel's
+	for _, tun := range []Tunnel{
+		{Server: "web", PID: os.Getpid(), ProcessStart: ProcessStart(os.Getpid())},
+		{Server: "db", PID: 0},
+		{Server: "prod/cache", PID: 0},
+		{Server: "reused", PID: os.Getpid(), ProcessStart: "earlier"},
+	} {
+		if err := s.Save(tun); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	stale, err := s.Prune()
+	if err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	if len(stale) != 3 || stale[0].Server != "db" || stale[1].Server != "prod/cache" || stale[2].Server != "reused" {
+		t.Errorf("Prune() = %+v, want db, prod/cache and reused", stale)
+	}
+
+	tunnels, _ := s.List()
+	if len(tunnels) != 1 || tunnels[0].Server != "web" {
+		t.Errorf("List() after Prune() = %+v, want only web", tunnels)
+	}
+}
+
+func TestTunnelReusedPID(t *testing.T) {
+	start := ProcessStart(os.Getpid())
+	if start == "" {
+		t.Skip("process start time not available")
+	}
+
+	if tun := (Tunnel{PID: os.Getpid(), ProcessStart: start}); !tun.Alive() {
+		t.Error("Alive() = false for the process that started the tunnel")
+	}
+
+	// Stopping a tunnel whose pid now belongs to another process leaves it
+	// running: here that process is the test itself
+	reused := Tunnel{Server: "web", PID: os.Getpid(), ProcessStart: "earlier"}
+	if reused.Alive() {
+		t.Error("Alive() = true for a process reusing the tunnel's pid")
+	}
+	if err := reused.Stop(); err != nil {
+		t.Errorf("Stop() error = %v", err)
+	}
+}
+
+func TestTunnelCheck(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer ln.Close()
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	closedPort := closed.Addr().(*net.TCPAddr).Port
+	closed.Close()
+
+	listen := func(p int) string { return "127.0.0.1:" + strconv.Itoa(p) }
+
+	tests := []struct {
+		name string
+		tun  Tunnel
+		want Health
+	}{
+		{"up", Tunnel{PID: os.Getpid(), Forwards: []config.Forward{
+			{Type: config.ForwardLocal, Listen: listen(port), Target: "db:5432"},
+			{Type: config.ForwardRemote, Listen: listen(closedPort), Target: "localhost:80"},
+		}}, HealthUp},
+		{"degraded", Tunnel{PID: os.Getpid(), Forwards: []config.Forward{
+			{Type: config.ForwardDynamic, Listen: listen(closedPort)},
+		}}, HealthDegraded},
+		{"down", Tunnel{PID: 0}, HealthDown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.tun.Check(time.Second); got != tt.want {
+				t.Errorf("Check() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileName(t *testing.T) {
+	tests := map[string]string{
+		"web-1.prod": "web-1.prod",
+		"prod/db":    "prod%2Fdb",
+		"a:b c":      "a%3Ab%20c",
+	}
+	for in, want := range tests {
+		if got := fileName(in); got != want {
+			t.Errorf("fileName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
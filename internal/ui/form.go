@@ -20,6 +20,7 @@ const (
 	fieldPort
 	fieldKey
 	fieldGroup
+	fieldForwards
 	fieldCount
 )
 
@@ -77,6 +78,12 @@ func NewFormModel(server *config.Server, groups []config.Group) FormModel {
 	inputs[fieldGroup].Width = 40
 	inputs[fieldGroup].Prompt = "Group:"
 
+	inputs[fieldForwards] = textinput.New()
+	inputs[fieldForwards].Placeholder = "L:5432:localhost:5432, D:1080 (optional)"
+	inputs[fieldForwards].CharLimit = 512
+	inputs[fieldForwards].Width = 40
+	inputs[fieldForwards].Prompt = "Fwds: "
+
 	isEdit := server != nil
 	if server == nil {
 		server = &config.Server{}
@@ -90,6 +97,7 @@ func NewFormModel(server *config.Server, groups []config.Group) FormModel {
 		}
 		inputs[fieldKey].SetValue(server.Key)
 		inputs[fieldGroup].SetValue(server.Group)
+		inputs[fieldForwards].SetValue(formatForwards(server.Forwards))
 	}
 
 	return FormModel{
@@ -186,6 +194,10 @@ func (m *FormModel) validate() error {
 		m.warning = warning
 	}
 
+	if _, err := config.ParseForwards(m.inputs[fieldForwards].Value()); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -201,6 +213,16 @@ func (m *FormModel) buildServer() {
 
 	m.server.Key = strings.TrimSpace(m.inputs[fieldKey].Value())
 	m.server.Group = strings.TrimSpace(m.inputs[fieldGroup].Value())
+	m.server.Forwards, _ = config.ParseForwards(m.inputs[fieldForwards].Value())
+}
+
+// formatForwards renders forwards as the comma separated list the form accepts
+func formatForwards(forwards []config.Forward) string {
+	specs := make([]string, len(forwards))
+	for i, f := range forwards {
+		specs[i] = f.String()
+	}
+	return strings.Join(specs, ", ")
 }
 
 func (m FormModel) View() string {
@@ -296,8 +296,11 @@ func TestFieldConstants(t *testing.T) {
 	if fieldGroup != 5 {
 		t.Error("fieldGroup should be 5")
 	}
-	if fieldCount != 6 {
-		t.Error("fieldCount should be 6")
+	if fieldForwards != 6 {
+		t.Error("fieldForwards should be 6")
+	}
+	if fieldCount != 7 {
+		t.Error("fieldCount should be 7")
 	}
 }
 
@@ -391,7 +394,7 @@ func TestFormModelUpdateEnterSubmit(t *testing.T) {
 	model := NewFormModel(nil, nil)
 	model.inputs[fieldName].SetValue("test")
 	model.inputs[fieldHost].SetValue("localhost")
-	model.focused = fieldGroup // Last field
+	model.focused = fieldForwards // Last field
 
 	newModel, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
 	if cmd == nil {
@@ -406,7 +409,7 @@ func TestFormModelUpdateEnterSubmit(t *testing.T) {
 func TestFormModelUpdateEnterSubmitInvalid(t *testing.T) {
 	model := NewFormModel(nil, nil)
 	// Leave name empty (invalid)
-	model.focused = fieldGroup // Last field
+	model.focused = fieldForwards // Last field
 
 	newModel, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
 	if cmd != nil {
@@ -446,3 +449,33 @@ func TestFormValidateKeyFileWarning(t *testing.T) {
 		t.Error("validate() should set warning for non-existent key file")
 	}
 }
+
+func TestFormForwards(t *testing.T) {
+	server := &config.Server{
+		Name: "db",
+		Host: "10.0.0.5",
+		Forwards: []config.Forward{
+			{Type: config.ForwardLocal, Listen: "5432", Target: "localhost:5432"},
+			{Type: config.ForwardDynamic, Listen: "1080"},
+		},
+	}
+
+	model := NewFormModel(server, nil)
+	if got := model.inputs[fieldForwards].Value(); got != "L:5432:localhost:5432, D:1080" {
+		t.Errorf("Forwards field = %q, want %q", got, "L:5432:localhost:5432, D:1080")
+	}
+
+	model.inputs[fieldForwards].SetValue("R:8080:localhost:80")
+	if err := model.validate(); err != nil {
+		t.Fatalf("validate() error = %v", err)
+	}
+	model.buildServer()
+	if len(model.server.Forwards) != 1 || model.server.Forwards[0].Type != config.ForwardRemote {
+		t.Errorf("Forwards = %+v, want one remote forward", model.server.Forwards)
+	}
+
+	model.inputs[fieldForwards].SetValue("X:1")
+	if err := model.validate(); err == nil {
+		t.Error("validate() should return error for invalid forward")
+	}
+}
@@ -1,14 +1,19 @@
 package ui
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/codoworks/sshto/internal/config"
+	"github.com/codoworks/sshto/internal/probe"
 )
 
 // ServerItem represents a server in the list
@@ -18,7 +23,11 @@ type ServerItem struct {
 }
 
 func (s ServerItem) FilterValue() string {
-	return s.Server.Name + " " + s.Server.Host + " " + s.Server.Group
+	value := s.Server.Name + " " + s.Server.Host + " " + s.Server.Group
+	if len(s.Server.Tags) > 0 {
+		value += " " + strings.Join(s.Server.Tags, " ")
+	}
+	return value
 }
 
 func (s ServerItem) Title() string {
@@ -36,9 +45,36 @@ func (s ServerItem) Description() string {
 	return desc
 }
 
+// GroupItem stands in for the servers of a collapsed group
+type GroupItem struct {
+	Group   string
+	Path    string
+	Servers []config.Server
+}
+
+func (g GroupItem) FilterValue() string {
+	values := []string{g.Path}
+	for _, s := range g.Servers {
+		values = append(values, s.Name)
+	}
+	return strings.Join(values, " ")
+}
+
+func (g GroupItem) Title() string {
+	return g.Path
+}
+
+func (g GroupItem) Description() string {
+	if len(g.Servers) == 1 {
+		return "1 server"
+	}
+	return fmt.Sprintf("%d servers", len(g.Servers))
+}
+
 // ServerItemDelegate handles rendering of server items
 type ServerItemDelegate struct {
 	groups map[string]*config.Group
+	status map[string]probe.Result // nil when reachability is not probed
 }
 
 func NewServerItemDelegate(groups []config.Group) ServerItemDelegate {
@@ -54,6 +90,11 @@ func (d ServerItemDelegate) Spacing() int                            { return 0
 func (d ServerItemDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
 
 func (d ServerItemDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	if g, ok := item.(GroupItem); ok {
+		d.renderGroup(w, g, index == m.Index())
+		return
+	}
+
 	s, ok := item.(ServerItem)
 	if !ok {
 		return
@@ -70,10 +111,21 @@ func (d ServerItemDelegate) Render(w io.Writer, m list.Model, index int, item li
 		}
 		title = GroupTag(s.Server.Group, color) + title
 	}
+	if len(s.Server.Tags) > 0 {
+		title += " " + TagList(s.Server.Tags)
+	}
 
 	// Build description
 	desc := s.Description()
 
+	if d.status != nil {
+		res, probed := d.status[s.Server.Name]
+		title = statusDot(res, probed) + " " + title
+		if probed && res.OK() {
+			desc += DimStyle.Render(" · " + res.Latency.Round(time.Millisecond).String())
+		}
+	}
+
 	// Apply styles
 	if isSelected {
 		title = SelectedItemStyle.Render("> " + title)
@@ -86,49 +138,321 @@ func (d ServerItemDelegate) Render(w io.Writer, m list.Model, index int, item li
 	fmt.Fprintf(w, "%s\n%s\n", title, desc)
 }
 
+// statusDot returns a dot colored by reachability, hollow while the probe is pending
+func statusDot(res probe.Result, probed bool) string {
+	switch {
+	case !probed:
+		return DimStyle.Render("○")
+	case res.OK():
+		return SuccessStyle.Render("●")
+	default:
+		return ErrorStyle.Render("●")
+	}
+}
+
+// renderGroup renders a collapsed group as a single entry
+func (d ServerItemDelegate) renderGroup(w io.Writer, g GroupItem, isSelected bool) {
+	color := "gray"
+	if group, ok := d.groups[g.Group]; ok && group.Color != "" {
+		color = group.Color
+	}
+	title := "▸ " + GroupTag(g.Path, color)
+	desc := g.Description() + " hidden"
+
+	if isSelected {
+		title = SelectedItemStyle.Render("> " + title)
+		desc = SelectedItemStyle.Copy().Bold(false).Render("  " + desc)
+	} else {
+		title = ItemStyle.Render("  " + title)
+		desc = DimStyle.Render("    " + desc)
+	}
+
+	fmt.Fprintf(w, "%s\n%s\n", title, desc)
+}
+
+var (
+	foldKey   = key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "fold/unfold group"))
+	foldUpKey = key.NewBinding(key.WithKeys("-"), key.WithHelp("-", "fold parent group"))
+)
+
+// ProbeFunc checks whether a server is reachable, giving up when ctx is done
+type ProbeFunc func(ctx context.Context, s config.Server) probe.Result
+
+// probeResultMsg carries the result of probing one server
+type probeResultMsg struct {
+	server string
+	result probe.Result
+}
+
+// maxConcurrentProbes limits how many servers are probed at once
+const maxConcurrentProbes = 16
+
 // ListModel is the bubbletea model for server selection
 type ListModel struct {
-	list     list.Model
-	selected *config.Server
-	quitting bool
+	list      list.Model
+	selected  *config.Server
+	quitting  bool
+	servers   []config.Server
+	groups    []config.Group
+	collapsed map[string]bool
+
+	probe      ProbeFunc
+	probeSem   chan struct{}
+	probeCtx   context.Context
+	stopProbes context.CancelFunc
+	probed     map[string]bool // servers whose probe has started
+	status     map[string]probe.Result
+}
+
+// ListOption customizes a ListModel
+type ListOption func(*ListModel)
+
+// WithScores orders servers by score, highest first, so the most likely
+// target is preselected. Servers without a score keep their config order.
+func WithScores(scores map[string]float64) ListOption {
+	return func(m *ListModel) {
+		if len(scores) == 0 {
+			return
+		}
+		servers := append([]config.Server(nil), m.servers...)
+		sort.SliceStable(servers, func(i, j int) bool {
+			return scores[servers[i].Name] > scores[servers[j].Name]
+		})
+		m.servers = servers
+	}
+}
+
+// WithProbe checks the servers on screen in the background, showing a status
+// dot and latency as the results arrive. Servers are probed as they come into
+// view, and probes still running when the list closes are cancelled.
+func WithProbe(fn ProbeFunc) ListOption {
+	return func(m *ListModel) {
+		m.probe = fn
+		m.probeSem = make(chan struct{}, maxConcurrentProbes)
+		m.probeCtx, m.stopProbes = context.WithCancel(context.Background())
+		m.probed = make(map[string]bool)
+		m.status = make(map[string]probe.Result)
+	}
 }
 
 // NewListModel creates a new list model
-func NewListModel(servers []config.Server, groups []config.Group) ListModel {
-	items := make([]list.Item, len(servers))
-	for i, s := range servers {
-		items[i] = ServerItem{Server: s}
+func NewListModel(servers []config.Server, groups []config.Group, opts ...ListOption) ListModel {
+	m := ListModel{
+		servers:   servers,
+		groups:    groups,
+		collapsed: make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(&m)
 	}
 
 	delegate := NewServerItemDelegate(groups)
-	l := list.New(items, delegate, 80, 20)
+	delegate.status = m.status
+	l := list.New(m.items(), delegate, 80, 20)
 	l.Title = "Select a server"
 	l.SetShowStatusBar(true)
 	l.SetFilteringEnabled(true)
 	l.Styles.Title = TitleStyle
 	l.Styles.HelpStyle = HelpStyle
+	l.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{foldKey}
+	}
+	l.AdditionalFullHelpKeys = func() []key.Binding {
+		return []key.Binding{foldKey, foldUpKey}
+	}
 
-	return ListModel{list: l}
+	m.list = l
+	return m
+}
+
+// items builds the list entries, replacing the servers of each collapsed
+// group with a single GroupItem at the position of its first server
+func (m ListModel) items() []list.Item {
+	var items []list.Item
+	shown := make(map[string]bool)
+	for _, s := range m.servers {
+		group := m.collapsedGroup(s.Group)
+		if group == "" {
+			items = append(items, ServerItem{Server: s})
+			continue
+		}
+		if !shown[group] {
+			shown[group] = true
+			items = append(items, GroupItem{
+				Group:   group,
+				Path:    m.groupPath(group),
+				Servers: FilterByGroupTree(m.servers, m.groups, group),
+			})
+		}
+	}
+	return items
+}
+
+// collapsedGroup returns the outermost collapsed group containing the named group
+func (m ListModel) collapsedGroup(name string) string {
+	var outer string
+	for _, g := range groupLineage(m.groups, name) {
+		if m.collapsed[g] {
+			outer = g
+		}
+	}
+	return outer
+}
+
+// groupPath returns the slash separated path of the named group
+func (m ListModel) groupPath(name string) string {
+	lineage := groupLineage(m.groups, name)
+	parts := make([]string, len(lineage))
+	for i, g := range lineage {
+		parts[len(lineage)-1-i] = g
+	}
+	return strings.Join(parts, "/")
+}
+
+// parentGroup returns the parent of the named group, if any
+func (m ListModel) parentGroup(name string) string {
+	if lineage := groupLineage(m.groups, name); len(lineage) > 1 {
+		return lineage[1]
+	}
+	return ""
+}
+
+// setCollapsed folds or unfolds a group and keeps the cursor on it
+func (m ListModel) setCollapsed(group string, collapsed bool) (ListModel, tea.Cmd) {
+	if collapsed {
+		m.collapsed[group] = true
+	} else {
+		// Unfolding also unfolds any nested groups hidden inside it
+		for g := range m.collapsed {
+			if inGroup(m.groups, g, group) {
+				delete(m.collapsed, g)
+			}
+		}
+	}
+
+	cmd := m.list.SetItems(m.items())
+	for i, item := range m.list.VisibleItems() {
+		switch item := item.(type) {
+		case GroupItem:
+			if item.Group == group {
+				m.list.Select(i)
+				return m, cmd
+			}
+		case ServerItem:
+			if !collapsed && inGroup(m.groups, item.Server.Group, group) {
+				m.list.Select(i)
+				return m, cmd
+			}
+		}
+	}
+	return m, cmd
+}
+
+// fold handles the fold keys for the selected item
+func (m ListModel) fold(up bool) (ListModel, tea.Cmd) {
+	switch item := m.list.SelectedItem().(type) {
+	case ServerItem:
+		if item.Server.Group != "" {
+			return m.setCollapsed(item.Server.Group, true)
+		}
+	case GroupItem:
+		if !up {
+			return m.setCollapsed(item.Group, false)
+		}
+		if parent := m.parentGroup(item.Group); parent != "" {
+			return m.setCollapsed(parent, true)
+		}
+	}
+	return m, nil
 }
 
 func (m ListModel) Init() tea.Cmd {
-	return nil
+	return m.probeVisible()
+}
+
+// probeVisible starts probing the servers on the current page that have not
+// been probed yet
+func (m ListModel) probeVisible() tea.Cmd {
+	if m.probe == nil {
+		return nil
+	}
+	items := m.list.VisibleItems()
+	start, end := m.list.Paginator.GetSliceBounds(len(items))
+
+	var cmds []tea.Cmd
+	for _, item := range items[start:end] {
+		s, ok := item.(ServerItem)
+		if !ok || m.probed[s.Server.Name] {
+			continue
+		}
+		m.probed[s.Server.Name] = true
+		cmds = append(cmds, m.probeCmd(s.Server))
+	}
+	return tea.Batch(cmds...)
 }
 
+// probeCmd probes a server off the UI goroutine
+func (m ListModel) probeCmd(s config.Server) tea.Cmd {
+	fn, sem, ctx := m.probe, m.probeSem, m.probeCtx
+	return func() tea.Msg {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			return nil
+		}
+		defer func() { <-sem }()
+		if ctx.Err() != nil {
+			return nil
+		}
+		return probeResultMsg{server: s.Name, result: fn(ctx, s)}
+	}
+}
+
+// Update handles a message, then probes the servers it brought into view or
+// cancels the probes when the list closes
 func (m ListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	m, cmd := m.update(msg)
+	if m.quitting {
+		if m.stopProbes != nil {
+			m.stopProbes()
+		}
+		return m, cmd
+	}
+	return m, tea.Batch(cmd, m.probeVisible())
+}
+
+func (m ListModel) update(msg tea.Msg) (ListModel, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.list.SetWidth(msg.Width)
 		m.list.SetHeight(msg.Height - 2)
 		return m, nil
 
+	case probeResultMsg:
+		if m.status != nil {
+			m.status[msg.server] = msg.result
+		}
+		return m, nil
+
 	case tea.KeyMsg:
+		if m.list.FilterState() != list.Filtering && m.collapsed != nil {
+			switch {
+			case key.Matches(msg, foldKey):
+				return m.fold(false)
+			case key.Matches(msg, foldUpKey):
+				return m.fold(true)
+			}
+		}
+
 		switch msg.String() {
 		case "enter":
-			if item, ok := m.list.SelectedItem().(ServerItem); ok {
+			switch item := m.list.SelectedItem().(type) {
+			case ServerItem:
 				m.selected = &item.Server
 				m.quitting = true
 				return m, tea.Quit
+			case GroupItem:
+				return m.setCollapsed(item.Group, false)
 			}
 		case "q", "esc", "ctrl+c":
 			m.quitting = true
@@ -153,6 +477,61 @@ func (m ListModel) Selected() *config.Server {
 	return m.selected
 }
 
+// FilterByGroupTree returns servers in group or, following each group's
+// parent in groups, any of its descendants
+func FilterByGroupTree(servers []config.Server, groups []config.Group, group string) []config.Server {
+	if group == "" {
+		return servers
+	}
+	var filtered []config.Server
+	for _, s := range servers {
+		if inGroup(groups, s.Group, group) {
+			filtered = append(filtered, s)
+		}
+	}
+	return filtered
+}
+
+// inGroup reports whether the named group is ancestor or nested below it
+func inGroup(groups []config.Group, name, ancestor string) bool {
+	for _, g := range groupLineage(groups, name) {
+		if strings.EqualFold(g, ancestor) {
+			return true
+		}
+	}
+	return false
+}
+
+// groupLineage returns a group name followed by the names of its parents
+func groupLineage(groups []config.Group, name string) []string {
+	cfg := config.Config{Groups: groups}
+	if chain := cfg.GroupAncestors(name); len(chain) > 0 {
+		names := make([]string, len(chain))
+		for i, g := range chain {
+			names[i] = g.Name
+		}
+		return names
+	}
+	if name == "" {
+		return nil
+	}
+	return []string{name}
+}
+
+// FilterByTags returns servers carrying any of tags, or all of them when matchAll is set
+func FilterByTags(servers []config.Server, tags []string, matchAll bool) []config.Server {
+	if len(tags) == 0 {
+		return servers
+	}
+	var filtered []config.Server
+	for _, s := range servers {
+		if s.MatchTags(tags, matchAll) {
+			filtered = append(filtered, s)
+		}
+	}
+	return filtered
+}
+
 // FilterByGroup returns a new list filtered by group
 func FilterByGroup(servers []config.Server, group string) []config.Server {
 	if group == "" {
@@ -1,11 +1,16 @@
 package ui
 
 import (
+	"context"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/codoworks/sshto/internal/config"
+	"github.com/codoworks/sshto/internal/probe"
 )
 
 func TestServerItemFilterValue(t *testing.T) {
@@ -19,6 +24,17 @@ func TestServerItemFilterValue(t *testing.T) {
 	}
 }
 
+func TestServerItemFilterValueTags(t *testing.T) {
+	item := ServerItem{
+		Server: config.Server{Name: "db1", Host: "10.0.0.1", Tags: []string{"db", "legacy"}},
+	}
+
+	expected := "db1 10.0.0.1  db legacy"
+	if item.FilterValue() != expected {
+		t.Errorf("FilterValue() = %q, want %q", item.FilterValue(), expected)
+	}
+}
+
 func TestServerItemTitle(t *testing.T) {
 	item := ServerItem{
 		Server: config.Server{Name: "web1"},
@@ -113,6 +129,32 @@ func TestNewListModel(t *testing.T) {
 	}
 }
 
+func TestNewListModelWithScores(t *testing.T) {
+	servers := []config.Server{
+		{Name: "web1", Host: "10.0.0.1"},
+		{Name: "db1", Host: "10.0.0.2"},
+		{Name: "cache1", Host: "10.0.0.3"},
+		{Name: "web2", Host: "10.0.0.4"},
+	}
+	scores := map[string]float64{"db1": 10, "web2": 200}
+
+	model := NewListModel(servers, nil, WithScores(scores))
+
+	want := []string{"web2", "db1", "web1", "cache1"}
+	items := model.list.Items()
+	for i, name := range want {
+		if got := items[i].(ServerItem).Server.Name; got != name {
+			t.Errorf("items[%d] = %q, want %q", i, got, name)
+		}
+	}
+	if item, ok := model.list.SelectedItem().(ServerItem); !ok || item.Server.Name != "web2" {
+		t.Errorf("SelectedItem() = %v, want web2 preselected", model.list.SelectedItem())
+	}
+	if servers[0].Name != "web1" {
+		t.Error("WithScores() should not reorder the caller's slice")
+	}
+}
+
 func TestListModelInit(t *testing.T) {
 	model := ListModel{}
 	cmd := model.Init()
@@ -121,6 +163,93 @@ func TestListModelInit(t *testing.T) {
 	}
 }
 
+func TestListModelWithProbe(t *testing.T) {
+	servers := []config.Server{
+		{Name: "up", Host: "10.0.0.1"},
+		{Name: "down", Host: "10.0.0.2"},
+	}
+	probeFn := func(ctx context.Context, s config.Server) probe.Result {
+		if s.Name == "up" {
+			return probe.Result{Server: s.Name, State: probe.StateReachable, Latency: 42 * time.Millisecond}
+		}
+		return probe.Result{Server: s.Name, State: probe.StateTimeout}
+	}
+
+	model := NewListModel(servers, nil, WithProbe(probeFn))
+	cmd := model.Init()
+	if cmd == nil {
+		t.Fatal("Init() should start probes when WithProbe is set")
+	}
+	if strings.Contains(model.View(), "42ms") {
+		t.Error("View() should not show latency before probes finish")
+	}
+
+	batch, ok := cmd().(tea.BatchMsg)
+	if !ok || len(batch) != len(servers) {
+		t.Fatalf("Init() command returned %T, want a batch of %d probes", cmd(), len(servers))
+	}
+
+	var m tea.Model = model
+	for _, probeCmd := range batch {
+		m, _ = m.Update(probeCmd())
+	}
+	model = m.(ListModel)
+
+	if model.status["up"].State != probe.StateReachable || model.status["down"].State != probe.StateTimeout {
+		t.Errorf("status = %+v", model.status)
+	}
+	if !strings.Contains(model.View(), "42ms") {
+		t.Error("View() should show the latency of reachable servers")
+	}
+}
+
+func TestListModelProbesVisibleServers(t *testing.T) {
+	var servers []config.Server
+	for i := 0; i < 100; i++ {
+		servers = append(servers, config.Server{Name: fmt.Sprintf("web%02d", i), Host: "10.0.0.1"})
+	}
+	probeFn := func(ctx context.Context, s config.Server) probe.Result {
+		<-ctx.Done()
+		return probe.Result{Server: s.Name, State: probe.StateTimeout}
+	}
+
+	model := NewListModel(servers, nil, WithProbe(probeFn))
+	batch, ok := model.Init()().(tea.BatchMsg)
+	if !ok || len(batch) == 0 || len(batch) >= len(servers) {
+		t.Fatalf("Init() started %d probes, want only the first page of %d servers", len(batch), len(servers))
+	}
+
+	// Paging down probes the servers that come into view, once each
+	m, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRight})
+	next, ok := cmd().(tea.BatchMsg)
+	if !ok || len(next) != len(batch) {
+		t.Fatalf("next page started %T, want %d probes", cmd(), len(batch))
+	}
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	if cmd != nil {
+		if _, ok := cmd().(tea.BatchMsg); ok {
+			t.Error("returning to the first page should not probe again")
+		}
+	}
+
+	// Closing the list cancels probes, running or waiting for a slot
+	done := make(chan tea.Msg, len(batch))
+	for _, probeCmd := range batch {
+		go func() { done <- probeCmd() }()
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	for range batch {
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("probes still running after the list closed")
+		}
+	}
+	if msg := next[0](); msg != nil {
+		t.Errorf("probe started after close returned %v, want nil", msg)
+	}
+}
+
 func TestFilterByGroup(t *testing.T) {
 	servers := []config.Server{
 		{Name: "web1", Host: "192.168.1.1", Group: "production"},
@@ -152,6 +281,141 @@ func TestFilterByGroup(t *testing.T) {
 	}
 }
 
+func TestFilterByTags(t *testing.T) {
+	servers := []config.Server{
+		{Name: "db1", Tags: []string{"db", "legacy"}},
+		{Name: "db2", Tags: []string{"db"}},
+		{Name: "node1", Tags: []string{"k8s-node"}},
+		{Name: "plain"},
+	}
+
+	tests := []struct {
+		name     string
+		tags     []string
+		matchAll bool
+		expected int
+	}{
+		{"no tags", nil, false, 4},
+		{"single tag", []string{"db"}, false, 2},
+		{"any of", []string{"legacy", "k8s-node"}, false, 2},
+		{"all of", []string{"db", "legacy"}, true, 1},
+		{"case insensitive", []string{"DB"}, false, 2},
+		{"unknown tag", []string{"web"}, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered := FilterByTags(servers, tt.tags, tt.matchAll)
+			if len(filtered) != tt.expected {
+				t.Errorf("FilterByTags(%v, %v) returned %d servers, want %d", tt.tags, tt.matchAll, len(filtered), tt.expected)
+			}
+		})
+	}
+}
+
+func TestFilterByGroupTree(t *testing.T) {
+	groups := []config.Group{
+		{Name: "prod"},
+		{Name: "eu", Parent: "prod"},
+		{Name: "web", Parent: "eu"},
+	}
+	servers := []config.Server{
+		{Name: "web1", Group: "web"},
+		{Name: "eu1", Group: "eu"},
+		{Name: "prod1", Group: "prod"},
+		{Name: "dev1", Group: "dev"},
+	}
+
+	tests := []struct {
+		group    string
+		expected int
+	}{
+		{"prod", 3},
+		{"EU", 2},
+		{"web", 1},
+		{"dev", 1},
+		{"", 4},
+		{"nonexistent", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.group, func(t *testing.T) {
+			filtered := FilterByGroupTree(servers, groups, tt.group)
+			if len(filtered) != tt.expected {
+				t.Errorf("FilterByGroupTree(%q) returned %d servers, want %d", tt.group, len(filtered), tt.expected)
+			}
+		})
+	}
+}
+
+func TestListModelFold(t *testing.T) {
+	groups := []config.Group{
+		{Name: "prod"},
+		{Name: "eu", Parent: "prod"},
+	}
+	servers := []config.Server{
+		{Name: "eu1", Host: "10.0.0.1", Group: "eu"},
+		{Name: "prod1", Host: "10.0.0.2", Group: "prod"},
+		{Name: "eu2", Host: "10.0.0.3", Group: "eu"},
+		{Name: "other", Host: "10.0.0.4"},
+	}
+	model := NewListModel(servers, groups)
+	space := tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	minus := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'-'}}
+
+	// Space on eu1 folds eu into one entry at eu1's position
+	newModel, _ := model.Update(space)
+	m := newModel.(ListModel)
+	items := m.list.Items()
+	if len(items) != 3 {
+		t.Fatalf("folded list has %d items, want 3", len(items))
+	}
+	g, ok := items[0].(GroupItem)
+	if !ok || g.Group != "eu" || g.Path != "prod/eu" || len(g.Servers) != 2 {
+		t.Fatalf("items[0] = %+v, want GroupItem for prod/eu with 2 servers", items[0])
+	}
+	if _, ok := m.list.SelectedItem().(GroupItem); !ok {
+		t.Error("cursor should stay on the folded group")
+	}
+
+	// Minus on the folded group folds its parent
+	newModel, _ = m.Update(minus)
+	m = newModel.(ListModel)
+	items = m.list.Items()
+	if len(items) != 2 {
+		t.Fatalf("folded parent list has %d items, want 2", len(items))
+	}
+	if g, ok := items[0].(GroupItem); !ok || g.Group != "prod" || len(g.Servers) != 3 {
+		t.Fatalf("items[0] = %+v, want GroupItem for prod with 3 servers", items[0])
+	}
+
+	// Enter on a folded group unfolds it and everything below it
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = newModel.(ListModel)
+	if m.Selected() != nil {
+		t.Error("Enter on a group should not select a server")
+	}
+	if len(m.list.Items()) != len(servers) {
+		t.Errorf("unfolded list has %d items, want %d", len(m.list.Items()), len(servers))
+	}
+}
+
+func TestGroupItemFilterValue(t *testing.T) {
+	item := GroupItem{
+		Group:   "eu",
+		Path:    "prod/eu",
+		Servers: []config.Server{{Name: "eu1"}, {Name: "eu2"}},
+	}
+
+	expected := "prod/eu eu1 eu2"
+	if item.FilterValue() != expected {
+		t.Errorf("FilterValue() = %q, want %q", item.FilterValue(), expected)
+	}
+	if item.Description() != "2 servers" {
+		t.Errorf("Description() = %q, want %q", item.Description(), "2 servers")
+	}
+}
+
 func TestListModelSelected(t *testing.T) {
 	model := ListModel{
 		selected: &config.Server{Name: "test"},
@@ -0,0 +1,62 @@
+package ui
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// PrefixWriter writes every line it receives to an underlying writer with a
+// prefix. Writers sharing a mutex never interleave within a line, so several
+// commands can report to one terminal at the same time.
+type PrefixWriter struct {
+	w      io.Writer
+	mu     *sync.Mutex
+	prefix []byte
+	buf    []byte
+}
+
+// NewPrefixWriter creates a PrefixWriter. mu guards w and may be shared.
+func NewPrefixWriter(w io.Writer, mu *sync.Mutex, prefix string) *PrefixWriter {
+	return &PrefixWriter{w: w, mu: mu, prefix: []byte(prefix)}
+}
+
+// Write buffers p and writes out each complete line
+func (p *PrefixWriter) Write(b []byte) (int, error) {
+	p.buf = append(p.buf, b...)
+
+	var out []byte
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			break
+		}
+		out = append(out, p.prefix...)
+		out = append(out, p.buf[:i+1]...)
+		p.buf = p.buf[i+1:]
+	}
+
+	if len(out) > 0 {
+		if err := p.write(out); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// Flush writes out a final line that did not end in a newline
+func (p *PrefixWriter) Flush() error {
+	if len(p.buf) == 0 {
+		return nil
+	}
+	out := append(append(append([]byte(nil), p.prefix...), p.buf...), '\n')
+	p.buf = nil
+	return p.write(out)
+}
+
+func (p *PrefixWriter) write(out []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err := p.w.Write(out)
+	return err
+}
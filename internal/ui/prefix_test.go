@@ -0,0 +1,61 @@
+package ui
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+)
+
+func TestPrefixWriter(t *testing.T) {
+	var out bytes.Buffer
+	var mu sync.Mutex
+	web := NewPrefixWriter(&out, &mu, "web1 | ")
+	db := NewPrefixWriter(&out, &mu, "db1  | ")
+
+	web.Write([]byte("up 3 days,"))
+	db.Write([]byte("active\n"))
+	web.Write([]byte(" load 0.1\nsecond line\npartial"))
+
+	if err := web.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if err := db.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	want := "db1  | active\n" +
+		"web1 | up 3 days, load 0.1\n" +
+		"web1 | second line\n" +
+		"web1 | partial\n"
+	if out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestPrefixWriterConcurrent(t *testing.T) {
+	var out bytes.Buffer
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, prefix := range []string{"a: ", "b: ", "c: "} {
+		wg.Add(1)
+		go func(prefix string) {
+			defer wg.Done()
+			w := NewPrefixWriter(&out, &mu, prefix)
+			for i := 0; i < 100; i++ {
+				w.Write([]byte("0123456789\n"))
+			}
+		}(prefix)
+	}
+	wg.Wait()
+
+	lines := bytes.Split(bytes.TrimSuffix(out.Bytes(), []byte("\n")), []byte("\n"))
+	if len(lines) != 300 {
+		t.Fatalf("got %d lines, want 300", len(lines))
+	}
+	for _, line := range lines {
+		if len(line) != len("a: 0123456789") {
+			t.Fatalf("interleaved line %q", line)
+		}
+	}
+}
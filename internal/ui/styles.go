@@ -1,6 +1,10 @@
 package ui
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
 
 var (
 	// Colors
@@ -56,6 +60,10 @@ var (
 	GroupTagStyle = lipgloss.NewStyle().
 			Padding(0, 1).
 			MarginRight(1)
+
+	TagStyle = lipgloss.NewStyle().
+			Foreground(ColorPrimary).
+			Italic(true)
 )
 
 // GroupTag returns a styled group tag
@@ -69,3 +77,11 @@ func GroupTag(name, color string) string {
 		Foreground(lipgloss.Color("0")).
 		Render(name)
 }
+
+// TagList returns styled server tags, e.g. "#db #legacy"
+func TagList(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	return TagStyle.Render("#" + strings.Join(tags, " #"))
+}
@@ -33,6 +33,19 @@ func TestGroupTag(t *testing.T) {
 	}
 }
 
+func TestTagList(t *testing.T) {
+	if got := TagList(nil); got != "" {
+		t.Errorf("TagList(nil) = %q, want empty", got)
+	}
+
+	result := TagList([]string{"db", "legacy"})
+	for _, want := range []string{"#db", "#legacy"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("TagList() = %q, should contain %q", result, want)
+		}
+	}
+}
+
 func TestStylesNotNil(t *testing.T) {
 	// Verify all styles are initialized
 	styles := []struct {